@@ -0,0 +1,103 @@
+// Command cshared exposes the parser as a C shared library, so scripts in other languages
+// can use the canonical parser instead of their own regexes:
+//
+//	go build -buildmode=c-shared -o libwarsowlog.so ./cmd/cshared
+//
+// Records are returned as JSON lines, like the warsowlog command writes them.
+// Every returned string must be released with warsowlog_free.
+package main
+
+/*
+#include <stdint.h>
+#include <stdlib.h>
+*/
+import "C"
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"os"
+	"runtime/cgo"
+	"strings"
+	"unsafe"
+
+	"github.com/fabienjuif/warsowlog"
+)
+
+// session is a parser with the records it emitted since the last call.
+type session struct {
+	parser *warsowlog.Parser
+	out    *bytes.Buffer
+}
+
+func newSession() *session {
+	out := &bytes.Buffer{}
+	return &session{
+		parser: warsowlog.NewParser(warsowlog.Options{MinPlayers: 2, Handler: slog.NewJSONHandler(out, nil)}),
+		out:    out,
+	}
+}
+
+// records returns the records emitted since the last call as a C string.
+func (s *session) records() *C.char {
+	records := C.CString(strings.TrimSpace(s.out.String()))
+	s.out.Reset()
+	return records
+}
+
+// warsowlog_new creates a parser, it must be released with warsowlog_close.
+//
+//export warsowlog_new
+func warsowlog_new() C.uintptr_t {
+	return C.uintptr_t(cgo.NewHandle(newSession()))
+}
+
+// warsowlog_parse_line parses a console line and returns the records it produced.
+//
+//export warsowlog_parse_line
+func warsowlog_parse_line(handle C.uintptr_t, line *C.char) *C.char {
+	s := cgo.Handle(handle).Value().(*session)
+	s.parser.Parse(context.Background(), C.GoString(line))
+	return s.records()
+}
+
+// warsowlog_flush returns the records still waiting for more lines, see Parser.Flush.
+//
+//export warsowlog_flush
+func warsowlog_flush(handle C.uintptr_t) *C.char {
+	s := cgo.Handle(handle).Value().(*session)
+	s.parser.Flush(context.Background())
+	return s.records()
+}
+
+// warsowlog_close releases a parser created by warsowlog_new.
+//
+//export warsowlog_close
+func warsowlog_close(handle C.uintptr_t) {
+	cgo.Handle(handle).Delete()
+}
+
+// warsowlog_parse_file parses a whole console log and returns its records, NULL if the file can not be read.
+//
+//export warsowlog_parse_file
+func warsowlog_parse_file(path *C.char) *C.char {
+	content, err := os.ReadFile(C.GoString(path))
+	if err != nil {
+		return nil
+	}
+	out := &bytes.Buffer{}
+	w := warsowlog.NewWriter(context.Background(), warsowlog.Options{MinPlayers: 2}, slog.NewJSONHandler(out, nil))
+	_, _ = w.Write(content)
+	_ = w.Close()
+	return C.CString(strings.TrimSpace(out.String()))
+}
+
+// warsowlog_free releases a string returned by the library.
+//
+//export warsowlog_free
+func warsowlog_free(s *C.char) {
+	C.free(unsafe.Pointer(s))
+}
+
+func main() {}
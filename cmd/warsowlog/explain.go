@@ -0,0 +1,92 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"maps"
+	"os"
+	"slices"
+
+	"github.com/fabienjuif/warsowlog"
+)
+
+// explain prints how the parser understands console lines: the rule matching each line, what it extracted,
+// the player names once sanitized, the issues met and the resulting records.
+// Lines are given as arguments, or read from stdin one by one so lines can be pasted interactively.
+// Each line is parsed after the previous ones, like in a console session, so players can be introduced first.
+func explain(args []string) {
+	// records are emitted before the decision of their line is dispatched, they are printed under it
+	records := &bytes.Buffer{}
+	var errs []error
+	parser := warsowlog.NewParser(warsowlog.Options{Handler: slog.NewJSONHandler(records, nil)})
+	parser.OnError(func(_ context.Context, err error) {
+		errs = append(errs, err)
+	})
+	parser.OnDecision(func(_ context.Context, d warsowlog.Decision) {
+		printDecision(os.Stdout, d, errs, records)
+		errs = nil
+	})
+
+	ctx := context.Background()
+	if len(args) > 0 {
+		for _, line := range args {
+			parser.Parse(ctx, line)
+		}
+	} else {
+		scanner := bufio.NewScanner(os.Stdin)
+		for scanner.Scan() {
+			parser.Parse(ctx, scanner.Text())
+		}
+	}
+	// a scoreboard ending the input is only summarized now
+	parser.Flush(ctx)
+	_, _ = records.WriteTo(os.Stdout)
+}
+
+func printDecision(w io.Writer, d warsowlog.Decision, errs []error, records *bytes.Buffer) {
+	fmt.Fprintln(w, "rule:", d.Rule)
+	for _, key := range slices.Sorted(maps.Keys(d.Fields)) {
+		value := d.Fields[key]
+		if sanitized, ok := d.Names[value]; ok && sanitized != value {
+			fmt.Fprintf(w, "  %s: %q -> %q\n", key, value, sanitized)
+		} else {
+			fmt.Fprintf(w, "  %s: %q\n", key, value)
+		}
+	}
+	for _, err := range errs {
+		fmt.Fprintln(w, "issue:", err)
+	}
+	_, _ = records.WriteTo(w)
+}
+
+// recordDecisions writes the decision taken for every line into the file, one JSON object per line.
+// The returned function closes the file.
+func recordDecisions(parser *warsowlog.Parser, path string) (func(), error) {
+	if path == "" {
+		return func() {}, nil
+	}
+	file, err := os.OpenFile(path, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+	writer := bufio.NewWriter(file)
+	encoder := json.NewEncoder(writer)
+	parser.OnDecision(func(_ context.Context, d warsowlog.Decision) {
+		if err := encoder.Encode(d); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing decision:", err)
+		}
+	})
+	return func() {
+		if err := writer.Flush(); err != nil {
+			fmt.Println("Error writing decisions file:", err)
+		}
+		if err := file.Close(); err != nil {
+			fmt.Println("Error closing decisions file:", err)
+		}
+	}, nil
+}
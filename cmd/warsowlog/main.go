@@ -0,0 +1,312 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"os/signal"
+	"runtime/debug"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fabienjuif/warsowlog"
+	"golang.org/x/sync/errgroup"
+)
+
+func main() {
+	if len(os.Args) > 1 && os.Args[1] == "reprocess" {
+		reprocess(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "explain" {
+		explain(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "stats" {
+		stats(os.Args[2:])
+		return
+	}
+	if len(os.Args) > 1 && os.Args[1] == "merge-players" {
+		mergePlayers(os.Args[2:])
+		return
+	}
+
+	path := flag.String("p", "", "Path to the file to write on top of stdout (like tee but unbuffered)")
+	decisions := flag.String("decisions", "", "Path to a file receiving how every line was parsed (rule and extracted values), to debug misparsed lines")
+	telemetryURL := flag.String("telemetry-url", "", "Opt-in: URL receiving the hashed shapes of the lines the parser does not recognize, never the lines themselves")
+	telemetryInterval := flag.Duration("telemetry-interval", time.Hour, "How often the shapes are submitted to -telemetry-url")
+	rawCopy := flag.String("raw-copy", "", "Path to a file receiving the untouched raw input, to reprocess it later")
+	options := optionsFlags(flag.CommandLine)
+	flag.Parse()
+	if *path == "" {
+		fmt.Println("Error: File path is required. Use -p <path>")
+		os.Exit(1)
+	}
+	opts, err := options()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+
+	writer, err := NewSplitWriter(*path)
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := writer.Close(); err != nil {
+			fmt.Println("Error closing file:", err)
+		}
+	}()
+
+	var raw *os.File
+	if *rawCopy != "" {
+		raw, err = os.OpenFile(*rawCopy, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+		if err != nil {
+			fmt.Println("Error opening raw copy file:", err)
+			os.Exit(1)
+		}
+		defer func() {
+			if err := raw.Close(); err != nil {
+				fmt.Println("Error closing raw copy file:", err)
+			}
+		}()
+	}
+
+	opts.Handler = slog.NewJSONHandler(writer, nil)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	parser := warsowlog.NewParser(opts)
+	saveRatings(parser, opts.Ratings)
+	closeDecisions, err := recordDecisions(parser, *decisions)
+	if err != nil {
+		fmt.Println("Error opening decisions file:", err)
+		os.Exit(1)
+	}
+	defer closeDecisions()
+	var unmatched *telemetry
+	if *telemetryURL != "" {
+		unmatched = newTelemetry(*telemetryURL)
+		unmatched.watch(parser)
+		go unmatched.run(ctx, *telemetryInterval)
+	}
+
+	scanner := bufio.NewScanner(os.Stdin)
+	for Scan(ctx, scanner) {
+		if raw != nil {
+			// the raw copy is written before any conversion so it can be reprocessed as is
+			if _, err := raw.WriteString(scanner.Text() + "\n"); err != nil {
+				fmt.Fprintln(os.Stderr, "Error writing raw copy:", err)
+			}
+		}
+		parser.Parse(ctx, scanner.Text())
+	}
+	parser.Flush(ctx)
+	if err := scanner.Err(); err != nil {
+		fmt.Fprintln(os.Stderr, "Error reading from stdin:", err)
+	}
+	if unmatched != nil {
+		// the context may be canceled already, the last shapes still deserve a try
+		unmatched.submit(context.Background())
+	}
+}
+
+// optionsFlags registers the parser flags on the flag set.
+// The returned function builds the options, it must be called once the flag set is parsed.
+func optionsFlags(fs *flag.FlagSet) func() (warsowlog.Options, error) {
+	evictAfter := fs.Duration("evict-after", 10*time.Minute, "Forget players disconnected since this long without any score (0 to disable)")
+	maxPlayers := fs.Int("max-players", 1024, "Maximum number of players kept in memory per game (0 for no limit)")
+	bots := fs.String("bots", string(warsowlog.BotsKeep), "Bot handling: keep (everything), drop-frags (bot vs bot frags are not counted) or exclude (drop-frags and no bots in summaries)")
+	minPlayers := fs.Int("min-players", 2, "Games with fewer human players are tagged unranked")
+	minDuration := fs.Duration("min-duration", 0, "Games shorter than this are tagged unranked")
+	nameChurn := fs.Int("name-churn", 3, "Flag an IP using more distinct names than this in a game (0 to disable)")
+	netsplitWindow := fs.Duration("netsplit-window", 30*time.Second, "Window in which player timeouts are counted to detect a netsplit")
+	netsplitRatio := fs.Float64("netsplit-ratio", 0.5, "Fraction of players timing out within the window that raises a server_netsplit alert (0 to disable)")
+	multikillWindow := fs.Duration("multikill-window", 3*time.Second, "Consecutive frags of a player within this window make a multikill (0 to disable)")
+	spreeFrags := fs.Int("spree", 5, "Number of frags without dying that makes a killing spree (0 to disable)")
+	sanitizer := fs.String("sanitizer", "default", "Player name sanitizer: default (strip any trailing color code) or strict (only strip the trailing ^7 of unknown names)")
+	mvp := mvpCriteria{}
+	fs.Var(&mvp, "mvp", "MVP criterion of a gametype, as gametype=criterion with frags, captures, time, rounds or none (repeatable), e.g. duel=frags; other gametypes use the criterion ranking their summary (captures in ctf, time in race, rounds in ca and bomb, frags otherwise)")
+	fields := summaryFields{}
+	fs.Var(&fields, "summary-field", "Field computed for each player in the end of game summary, as name=expression (repeatable), e.g. points=winner*3+draw")
+	ratings := fs.String("ratings", "", "Path to a JSON file keeping the ELO ratings of the players, updated after every ranked game")
+	ratingSystem := fs.String("rating-system", string(warsowlog.RatingELO), "Rating system of -ratings: elo or glicko2 (rating, deviation and volatility, better for players coming now and then)")
+	lite := fs.Bool("lite", false, "Low resource profile for small hosts: caps memory, keeps fewer players and disables streaks, unless set explicitly")
+
+	return func() (warsowlog.Options, error) {
+		botPolicy, err := warsowlog.ParseBotPolicy(*bots)
+		if err != nil {
+			return warsowlog.Options{}, err
+		}
+		nameSanitizer, err := warsowlog.ParseNameSanitizer(*sanitizer)
+		if err != nil {
+			return warsowlog.Options{}, err
+		}
+		opts := warsowlog.Options{
+			EvictAfter:      *evictAfter,
+			MaxPlayers:      *maxPlayers,
+			Bots:            botPolicy,
+			MinPlayers:      *minPlayers,
+			MinDuration:     *minDuration,
+			NameChurn:       *nameChurn,
+			MultikillWindow: *multikillWindow,
+			SpreeFrags:      *spreeFrags,
+			NetsplitWindow:  *netsplitWindow,
+			NetsplitRatio:   *netsplitRatio,
+			Sanitizer:       nameSanitizer,
+			SummaryFields:   fields,
+			MVP:             warsowlog.MVPCriteria(mvp),
+		}
+		if *ratings != "" {
+			system, err := warsowlog.ParseRatingSystem(*ratingSystem)
+			if err != nil {
+				return warsowlog.Options{}, err
+			}
+			opts.Ratings, err = warsowlog.LoadRatings(*ratings, system)
+			if err != nil {
+				return warsowlog.Options{}, fmt.Errorf("loading ratings: %w", err)
+			}
+		}
+		if *lite {
+			liteProfile(fs, &opts)
+		}
+		return opts, nil
+	}
+}
+
+// liteMemoryLimit is the soft memory limit of the lite profile,
+// warsowlog runs next to the game server on hosts with as few as 512MB.
+const liteMemoryLimit = 64 << 20
+
+// liteProfile tunes the options and the runtime for small hosts, the flags set explicitly are kept.
+func liteProfile(fs *flag.FlagSet, opts *warsowlog.Options) {
+	set := make(map[string]bool)
+	fs.Visit(func(f *flag.Flag) { set[f.Name] = true })
+	if !set["max-players"] {
+		opts.MaxPlayers = 64
+	}
+	if !set["evict-after"] {
+		opts.EvictAfter = time.Minute
+	}
+	if !set["multikill-window"] {
+		opts.MultikillWindow = 0
+	}
+	if !set["spree"] {
+		opts.SpreeFrags = 0
+	}
+	debug.SetMemoryLimit(liteMemoryLimit)
+	// collect more often, the heap stays small and so does the memory taken from the game server
+	debug.SetGCPercent(50)
+}
+
+// saveRatings saves the ratings after every match so a stopped parser does not lose them.
+func saveRatings(parser *warsowlog.Parser, ratings *warsowlog.Ratings) {
+	if ratings == nil {
+		return
+	}
+	parser.OnMatchEnd(func(context.Context, warsowlog.MatchEndEvent) {
+		if err := ratings.Save(); err != nil {
+			fmt.Fprintln(os.Stderr, "Error saving ratings:", err)
+		}
+	})
+}
+
+var (
+	ErrEOF = fmt.Errorf("EOF")
+)
+
+func Scan(ctx context.Context, s *bufio.Scanner) bool {
+	select {
+	case <-ctx.Done():
+		return false
+	default:
+		return s.Scan()
+	}
+}
+
+type SplitWriter struct {
+	stdout io.Writer
+	file   *os.File
+}
+
+// NewSplitWriter creates a new SplitWriter.
+func NewSplitWriter(filePath string) (*SplitWriter, error) {
+	// Open the file for writing, create if not exists, append if exists.
+	file, err := os.OpenFile(filePath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return nil, err
+	}
+
+	return &SplitWriter{
+		stdout: os.Stdout, // Writes to standard output
+		file:   file,      // Writes to the file
+	}, nil
+}
+
+func (w *SplitWriter) Write(p []byte) (n int, err error) {
+	eg := &errgroup.Group{}
+	eg.Go(func() error {
+		n, err = w.stdout.Write(p)
+		return err
+	})
+	eg.Go(func() error {
+		_, err = w.file.Write(p)
+		return err
+	})
+	return n, eg.Wait()
+}
+
+func (w *SplitWriter) Close() error {
+	return w.file.Close()
+}
+
+// summaryFields is a flag.Value collecting repeated -summary-field flags.
+type summaryFields []warsowlog.SummaryField
+
+func (f *summaryFields) String() string {
+	names := make([]string, 0, len(*f))
+	for _, field := range *f {
+		names = append(names, field.Name)
+	}
+	return strings.Join(names, ",")
+}
+
+func (f *summaryFields) Set(definition string) error {
+	field, err := warsowlog.ParseSummaryField(definition)
+	if err != nil {
+		return err
+	}
+	*f = append(*f, field)
+	return nil
+}
+
+// mvpCriteria is a flag.Value collecting repeated -mvp flags.
+type mvpCriteria warsowlog.MVPCriteria
+
+func (c *mvpCriteria) String() string {
+	definitions := make([]string, 0, len(*c))
+	for gameType, strategy := range *c {
+		name := "none"
+		if strategy != nil {
+			name = strategy.Name()
+		}
+		definitions = append(definitions, gameType+"="+name)
+	}
+	return strings.Join(definitions, ",")
+}
+
+func (c *mvpCriteria) Set(definition string) error {
+	gameType, strategy, err := warsowlog.ParseMVPCriterion(definition)
+	if err != nil {
+		return err
+	}
+	(*c)[gameType] = strategy
+	return nil
+}
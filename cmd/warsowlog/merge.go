@@ -0,0 +1,137 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io/fs"
+	"os"
+	"time"
+
+	"github.com/fabienjuif/warsowlog"
+	"github.com/fabienjuif/warsowlog/color"
+)
+
+// mergeEntry is a merge of the undo log, with the ratings as they were before it.
+type mergeEntry struct {
+	Time      time.Time                    `json:"time"`
+	Canonical string                       `json:"canonical"`
+	Aliases   []string                     `json:"aliases"`
+	Before    map[string]*warsowlog.Rating `json:"before"`
+}
+
+// mergePlayers merges the ratings of a player known under several names into the canonical one.
+// Every merge is appended to an undo log, -undo reverts the last one.
+func mergePlayers(args []string) {
+	fs := flag.NewFlagSet("merge-players", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: warsowlog merge-players -ratings <file> [flags] <canonical> <alias>...")
+		fmt.Fprintln(fs.Output(), "       warsowlog merge-players -ratings <file> -undo")
+		fs.PrintDefaults()
+	}
+	ratingsPath := fs.String("ratings", "", "Path to the ratings file to merge the players in")
+	ratingSystem := fs.String("rating-system", string(warsowlog.RatingELO), "Rating system of the ratings file: elo or glicko2")
+	undoPath := fs.String("undo-log", "", "Path to the undo log (default: the ratings file with a .undo suffix)")
+	undo := fs.Bool("undo", false, "Revert the last merge of the undo log")
+	_ = fs.Parse(args)
+	if *ratingsPath == "" || (!*undo && fs.NArg() < 2) {
+		fs.Usage()
+		os.Exit(1)
+	}
+	if *undoPath == "" {
+		*undoPath = *ratingsPath + ".undo"
+	}
+
+	system, err := warsowlog.ParseRatingSystem(*ratingSystem)
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	ratings, err := warsowlog.LoadRatings(*ratingsPath, system)
+	if err != nil {
+		fmt.Println("Error loading ratings:", err)
+		os.Exit(1)
+	}
+
+	if *undo {
+		err = undoMerge(ratings, *undoPath)
+	} else {
+		err = merge(ratings, *undoPath, color.Strip(fs.Arg(0)), stripAll(fs.Args()[1:]))
+	}
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+}
+
+// merge merges the aliases into the canonical name, the undo log is written before the ratings
+// so a merge can always be reverted.
+func merge(ratings *warsowlog.Ratings, undoPath, canonical string, aliases []string) error {
+	before, err := ratings.Merge(canonical, aliases...)
+	if err != nil {
+		return err
+	}
+	entry := mergeEntry{Time: time.Now(), Canonical: canonical, Aliases: aliases, Before: before}
+	line, err := json.Marshal(entry)
+	if err != nil {
+		return err
+	}
+	file, err := os.OpenFile(undoPath, os.O_APPEND|os.O_CREATE|os.O_WRONLY, 0644)
+	if err != nil {
+		return err
+	}
+	if _, err := file.Write(append(line, '\n')); err != nil {
+		file.Close()
+		return err
+	}
+	if err := file.Close(); err != nil {
+		return err
+	}
+	if err := ratings.Save(); err != nil {
+		return err
+	}
+	fmt.Printf("Merged %v into %q\n", aliases, canonical)
+	return nil
+}
+
+// undoMerge reverts the last merge of the undo log and removes it from the log.
+func undoMerge(ratings *warsowlog.Ratings, undoPath string) error {
+	data, err := os.ReadFile(undoPath)
+	if errors.Is(err, fs.ErrNotExist) {
+		return fmt.Errorf("no merge to undo")
+	}
+	if err != nil {
+		return err
+	}
+	lines := bytes.Split(bytes.TrimSpace(data), []byte("\n"))
+	if len(lines[len(lines)-1]) == 0 {
+		return fmt.Errorf("no merge to undo")
+	}
+	var entry mergeEntry
+	if err := json.Unmarshal(lines[len(lines)-1], &entry); err != nil {
+		return fmt.Errorf("reading the undo log: %w", err)
+	}
+	ratings.Restore(entry.Before)
+	if err := ratings.Save(); err != nil {
+		return err
+	}
+	remaining := bytes.Join(lines[:len(lines)-1], []byte("\n"))
+	if len(remaining) > 0 {
+		remaining = append(remaining, '\n')
+	}
+	if err := os.WriteFile(undoPath, remaining, 0644); err != nil {
+		return err
+	}
+	fmt.Printf("Reverted the merge of %v into %q from %s\n", entry.Aliases, entry.Canonical, entry.Time.Format(time.DateTime))
+	return nil
+}
+
+func stripAll(names []string) []string {
+	stripped := make([]string, 0, len(names))
+	for _, name := range names {
+		stripped = append(stripped, color.Strip(name))
+	}
+	return stripped
+}
@@ -0,0 +1,104 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"flag"
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/fabienjuif/warsowlog"
+)
+
+// reprocess replays raw console logs (as written by -raw-copy) through the current parser,
+// so the JSON output can be regenerated once parsing bugs are fixed.
+// Files are replayed in the given order, with a single parser, like one long console session.
+func reprocess(args []string) {
+	fs := flag.NewFlagSet("reprocess", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: warsowlog reprocess -p <output> [flags] <raw log>...")
+		fs.PrintDefaults()
+	}
+	path := fs.String("p", "", "Path to the file to write the regenerated records to, on top of stdout")
+	decisions := fs.String("decisions", "", "Path to a file receiving how every line was parsed (rule and extracted values)")
+	options := optionsFlags(fs)
+	_ = fs.Parse(args)
+	if *path == "" || fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+	opts, err := options()
+	if err != nil {
+		fmt.Println("Error:", err)
+		os.Exit(1)
+	}
+	if opts.Ratings != nil && len(opts.Ratings.Players) > 0 {
+		// the replayed games may already be counted in the file, they would be rated twice
+		fmt.Println("Error: reprocess rebuilds the ratings from scratch,", fs.Lookup("ratings").Value, "already holds some")
+		os.Exit(1)
+	}
+	replayProfile(fs, &opts)
+
+	writer, err := NewSplitWriter(*path)
+	if err != nil {
+		fmt.Println("Error opening file:", err)
+		os.Exit(1)
+	}
+	defer func() {
+		if err := writer.Close(); err != nil {
+			fmt.Println("Error closing file:", err)
+		}
+	}()
+
+	opts.Handler = slog.NewJSONHandler(writer, nil)
+
+	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, os.Kill, syscall.SIGINT, syscall.SIGTERM)
+	defer cancel()
+
+	parser := warsowlog.NewParser(opts)
+	saveRatings(parser, opts.Ratings)
+	closeDecisions, err := recordDecisions(parser, *decisions)
+	if err != nil {
+		fmt.Println("Error opening decisions file:", err)
+		os.Exit(1)
+	}
+	defer closeDecisions()
+	for _, name := range fs.Args() {
+		if err := replayFile(ctx, parser, name); err != nil {
+			fmt.Fprintln(os.Stderr, "Error reprocessing", name+":", err)
+			os.Exit(1)
+		}
+	}
+	parser.Flush(ctx)
+}
+
+// replayProfile disables the features measuring time between lines: a replay runs much faster
+// than the games it replays, they would make up durations, multikills and netsplits.
+func replayProfile(fs *flag.FlagSet, opts *warsowlog.Options) {
+	fs.Visit(func(f *flag.Flag) {
+		switch f.Name {
+		case "min-duration", "multikill-window", "netsplit-ratio":
+			fmt.Fprintf(os.Stderr, "Ignoring -%s: the raw logs do not keep the time of the lines\n", f.Name)
+		}
+	})
+	opts.MinDuration = 0
+	opts.MultikillWindow = 0
+	opts.NetsplitRatio = 0
+}
+
+func replayFile(ctx context.Context, parser *warsowlog.Parser, name string) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	for Scan(ctx, scanner) {
+		parser.Parse(ctx, scanner.Text())
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,201 @@
+package main
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"sort"
+	"strings"
+	"text/tabwriter"
+)
+
+// stats prints leaderboards from the end of game summaries of JSON logs written by warsowlog.
+func stats(args []string) {
+	fs := flag.NewFlagSet("stats", flag.ExitOnError)
+	fs.Usage = func() {
+		fmt.Fprintln(fs.Output(), "Usage: warsowlog stats [flags] <json log>...")
+		fs.PrintDefaults()
+	}
+	top := fs.Int("top", 10, "Number of players in each leaderboard")
+	minGames := fs.Int("min-games", 3, "Games a player must have played to appear in the K/D leaderboard")
+	asJSON := fs.Bool("json", false, "Print the leaderboards as JSON instead of tables")
+	_ = fs.Parse(args)
+	if fs.NArg() == 0 {
+		fs.Usage()
+		os.Exit(1)
+	}
+
+	players := make(map[string]*playerStats)
+	for _, name := range fs.Args() {
+		if err := readStats(name, players); err != nil {
+			fmt.Fprintln(os.Stderr, "Error reading", name+":", err)
+			os.Exit(1)
+		}
+	}
+
+	boards := buildLeaderboards(players, *top, *minGames)
+	if *asJSON {
+		encoder := json.NewEncoder(os.Stdout)
+		encoder.SetIndent("", "  ")
+		if err := encoder.Encode(boards); err != nil {
+			fmt.Fprintln(os.Stderr, "Error writing leaderboards:", err)
+			os.Exit(1)
+		}
+		return
+	}
+	printLeaderboards(os.Stdout, boards)
+}
+
+// playerStats are the totals of a player across games, players are known by their name without colors.
+type playerStats struct {
+	Name           string  `json:"name"`
+	Games          int     `json:"games"`
+	Frags          int     `json:"frags"`
+	Deaths         int     `json:"deaths"`
+	KD             float64 `json:"kd"`
+	FavoriteWeapon string  `json:"favorite_weapon,omitempty"`
+	weapons        map[string]int
+}
+
+// summaryRecord is the part of an end of game summary the leaderboards are made of.
+type summaryRecord struct {
+	FullGame bool `json:"full_game"`
+	Unranked bool `json:"unranked"`
+	Players  map[string]struct {
+		TextName string `json:"text_name"`
+		IsBot    bool   `json:"is_bot"`
+		Deaths   int    `json:"deaths"`
+	} `json:"players"`
+	Scores map[string]map[string]json.RawMessage `json:"scores"`
+}
+
+// readStats adds the ranked summaries of the JSON log to the players, bots aside.
+func readStats(name string, players map[string]*playerStats) error {
+	file, err := os.Open(name)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	// summaries of crowded games are long lines
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Bytes()
+		if !bytes.Contains(line, []byte(`"full_game":true`)) {
+			continue
+		}
+		var summary summaryRecord
+		// unranked games, like 1v0 warmups, do not count in the leaderboards
+		if err := json.Unmarshal(line, &summary); err != nil || !summary.FullGame || summary.Unranked {
+			continue
+		}
+		for name, player := range summary.Players {
+			if player.IsBot {
+				continue
+			}
+			stats, ok := players[player.TextName]
+			if !ok {
+				stats = &playerStats{Name: player.TextName, weapons: make(map[string]int)}
+				players[player.TextName] = stats
+			}
+			stats.Games++
+			stats.Deaths += player.Deaths
+			for victim, score := range summary.Scores[name] {
+				if victim == "@@weapons@@" {
+					var weapons map[string]int
+					_ = json.Unmarshal(score, &weapons)
+					for weapon, frags := range weapons {
+						stats.weapons[weapon] += frags
+					}
+					continue
+				}
+				// the own key of the player holds its suicides, as negative points
+				if strings.HasPrefix(victim, "@@") || victim == name {
+					continue
+				}
+				var frags int
+				_ = json.Unmarshal(score, &frags)
+				stats.Frags += frags
+			}
+		}
+	}
+	return scanner.Err()
+}
+
+// leaderboards are the rankings printed by stats.
+type leaderboards struct {
+	TopFraggers []*playerStats `json:"top_fraggers"`
+	BestKD      []*playerStats `json:"best_kd"`
+	MostGames   []*playerStats `json:"most_games"`
+}
+
+func buildLeaderboards(players map[string]*playerStats, top, minGames int) leaderboards {
+	all := make([]*playerStats, 0, len(players))
+	for _, p := range players {
+		p.KD = float64(p.Frags)
+		if p.Deaths > 0 {
+			p.KD = float64(p.Frags) / float64(p.Deaths)
+		}
+		for weapon, frags := range p.weapons {
+			if frags > p.weapons[p.FavoriteWeapon] || (frags == p.weapons[p.FavoriteWeapon] && weapon < p.FavoriteWeapon) {
+				p.FavoriteWeapon = weapon
+			}
+		}
+		all = append(all, p)
+	}
+	kdPlayers := make([]*playerStats, 0, len(all))
+	for _, p := range all {
+		if p.Games >= minGames {
+			kdPlayers = append(kdPlayers, p)
+		}
+	}
+	return leaderboards{
+		TopFraggers: rank(all, top, func(p *playerStats) float64 { return float64(p.Frags) }),
+		BestKD:      rank(kdPlayers, top, func(p *playerStats) float64 { return p.KD }),
+		MostGames:   rank(all, top, func(p *playerStats) float64 { return float64(p.Games) }),
+	}
+}
+
+// rank returns the top players by value, ties are broken by name.
+func rank(players []*playerStats, top int, value func(*playerStats) float64) []*playerStats {
+	ranked := make([]*playerStats, len(players))
+	copy(ranked, players)
+	sort.Slice(ranked, func(i, j int) bool {
+		if vi, vj := value(ranked[i]), value(ranked[j]); vi != vj {
+			return vi > vj
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+	if len(ranked) > top {
+		ranked = ranked[:top]
+	}
+	return ranked
+}
+
+func printLeaderboards(w io.Writer, boards leaderboards) {
+	sections := []struct {
+		title   string
+		players []*playerStats
+	}{
+		{"Top fraggers", boards.TopFraggers},
+		{"Best K/D", boards.BestKD},
+		{"Most games", boards.MostGames},
+	}
+	for i, section := range sections {
+		if i > 0 {
+			fmt.Fprintln(w)
+		}
+		fmt.Fprintln(w, section.title)
+		tw := tabwriter.NewWriter(w, 0, 0, 2, ' ', 0)
+		fmt.Fprintln(tw, "#\tPLAYER\tFRAGS\tDEATHS\tK/D\tGAMES\tFAVORITE WEAPON")
+		for pos, p := range section.players {
+			fmt.Fprintf(tw, "%d\t%s\t%d\t%d\t%.2f\t%d\t%s\n", pos+1, p.Name, p.Frags, p.Deaths, p.KD, p.Games, p.FavoriteWeapon)
+		}
+		_ = tw.Flush()
+	}
+}
@@ -0,0 +1,126 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/fabienjuif/warsowlog"
+)
+
+// telemetry counts the shapes of the lines the parser does not recognize and submits them
+// to the maintainers, so the parser can learn lines met in the wild. It is opt-in, see -telemetry-url.
+// Only hashes of the shapes leave the host, never the lines.
+type telemetry struct {
+	url    string
+	client *http.Client
+
+	mu     sync.Mutex
+	shapes map[string]int
+}
+
+func newTelemetry(url string) *telemetry {
+	return &telemetry{
+		url:    url,
+		client: &http.Client{Timeout: 10 * time.Second},
+		shapes: make(map[string]int),
+	}
+}
+
+// watch counts the unmatched lines of the parser.
+func (t *telemetry) watch(parser *warsowlog.Parser) {
+	parser.OnError(func(_ context.Context, err error) {
+		var parseErr *warsowlog.ParseError
+		if !errors.As(err, &parseErr) || !errors.Is(err, warsowlog.ErrUnmatchedLine) {
+			return
+		}
+		hash := sha256.Sum256([]byte(lineShape(parseErr.Line)))
+		t.mu.Lock()
+		t.shapes[hex.EncodeToString(hash[:])]++
+		t.mu.Unlock()
+	})
+}
+
+// run submits the shapes every interval until the context is done, then submits the last ones.
+func (t *telemetry) run(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+	for {
+		select {
+		case <-ticker.C:
+			t.submit(ctx)
+		case <-ctx.Done():
+			return
+		}
+	}
+}
+
+// submit sends the shapes counted since the last submission, they are kept for the next one on failure.
+func (t *telemetry) submit(ctx context.Context) {
+	t.mu.Lock()
+	shapes := t.shapes
+	t.shapes = make(map[string]int)
+	t.mu.Unlock()
+	if len(shapes) == 0 {
+		return
+	}
+
+	err := t.post(ctx, shapes)
+	if err == nil {
+		return
+	}
+	fmt.Fprintln(os.Stderr, "Error submitting telemetry:", err)
+	t.mu.Lock()
+	for hash, count := range shapes {
+		t.shapes[hash] += count
+	}
+	t.mu.Unlock()
+}
+
+func (t *telemetry) post(ctx context.Context, shapes map[string]int) error {
+	body, err := json.Marshal(struct {
+		Shapes map[string]int `json:"shapes"`
+	}{shapes})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, t.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	res, err := t.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer res.Body.Close()
+	if res.StatusCode >= 300 {
+		return fmt.Errorf("unexpected status %s", res.Status)
+	}
+	return nil
+}
+
+// lineShape returns the line with its variable parts masked: words carrying a color code
+// (player names) or a digit (IPs, scores, times) become "*", the other words are kept lower cased.
+// Lines of the same kind share the same shape whoever the players are.
+func lineShape(line string) string {
+	words := strings.Fields(line)
+	for i, word := range words {
+		if strings.ContainsRune(word, '^') || strings.ContainsFunc(word, unicode.IsDigit) {
+			words[i] = "*"
+		} else {
+			words[i] = strings.ToLower(word)
+		}
+	}
+	return strings.Join(words, " ")
+}
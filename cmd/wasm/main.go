@@ -0,0 +1,34 @@
+//go:build js && wasm
+
+// Command wasm exposes the parser to JavaScript, so a console log can be analyzed in the browser
+// without being uploaded. See warsowlog.js for the shim loading it.
+package main
+
+import (
+	"bytes"
+	"context"
+	"log/slog"
+	"strings"
+	"syscall/js"
+
+	"github.com/fabienjuif/warsowlog"
+)
+
+func main() {
+	js.Global().Set("warsowlogParse", js.FuncOf(parse))
+	// the functions are only callable while the program runs
+	select {}
+}
+
+// parse takes the content of a console log and returns the records as JSON lines,
+// like the warsowlog command writes them.
+func parse(_ js.Value, args []js.Value) any {
+	if len(args) == 0 {
+		return ""
+	}
+	out := &bytes.Buffer{}
+	w := warsowlog.NewWriter(context.Background(), warsowlog.Options{MinPlayers: 2}, slog.NewJSONHandler(out, nil))
+	_, _ = w.Write([]byte(args[0].String()))
+	_ = w.Close()
+	return strings.TrimSpace(out.String())
+}
@@ -0,0 +1,18 @@
+// Loads warsowlog.wasm and parses console logs in the browser.
+// wasm_exec.js, from the lib/wasm directory of the Go distribution, must be loaded first.
+//
+//	const warsowlog = await loadWarsowlog("warsowlog.wasm");
+//	const records = warsowlog.parse(await file.text());
+//	const summaries = records.filter((r) => r.full_game);
+async function loadWarsowlog(url) {
+  const go = new Go();
+  const { instance } = await WebAssembly.instantiateStreaming(fetch(url), go.importObject);
+  // the program never returns, it keeps the exported function alive
+  go.run(instance);
+  return {
+    parse(text) {
+      const lines = globalThis.warsowlogParse(text);
+      return lines === "" ? [] : lines.split("\n").map((line) => JSON.parse(line));
+    },
+  };
+}
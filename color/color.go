@@ -0,0 +1,189 @@
+// Package color handles Warsow color codes: a caret followed by a digit, like ^1 for red.
+//
+// It can tokenize and strip colored strings, and convert them from and to ANSI escape codes and HTML.
+package color
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Token is a piece of a Warsow colored string, either a color code or some text.
+type Token struct {
+	// Raw is the token as it appears in the colored string
+	Raw string
+	// Text is the displayed text, empty for color codes
+	Text string
+	// Color is the color digit (0 to 9) for color codes, -1 for text
+	Color int
+}
+
+func (t Token) IsColor() bool {
+	return t.Color >= 0
+}
+
+// Tokenize splits a Warsow colored string into color codes and text.
+// A color code is a caret followed by a single digit, "^^" is an escaped caret displayed as "^",
+// any other caret is displayed as is.
+// k^99 is the text "k", the color 9 and the text "9".
+func Tokenize(s string) []Token {
+	tokens := []Token{}
+	text := strings.Builder{}
+	raw := strings.Builder{}
+	flush := func() {
+		if raw.Len() > 0 {
+			tokens = append(tokens, Token{Raw: raw.String(), Text: text.String(), Color: -1})
+			raw.Reset()
+			text.Reset()
+		}
+	}
+
+	for i := 0; i < len(s); i++ {
+		if s[i] != '^' || i == len(s)-1 {
+			raw.WriteByte(s[i])
+			text.WriteByte(s[i])
+			continue
+		}
+		next := s[i+1]
+		switch {
+		case next >= '0' && next <= '9':
+			flush()
+			tokens = append(tokens, Token{Raw: s[i : i+2], Color: int(next - '0')})
+			i++
+		case next == '^':
+			raw.WriteString("^^")
+			text.WriteByte('^')
+			i++
+		default:
+			raw.WriteByte('^')
+			text.WriteByte('^')
+		}
+	}
+	flush()
+	return tokens
+}
+
+// Strip returns the displayed text of a Warsow colored string.
+func Strip(s string) string {
+	sb := strings.Builder{}
+	for _, t := range Tokenize(s) {
+		sb.WriteString(t.Text)
+	}
+	return sb.String()
+}
+
+// CutTrailing removes the color code ending the string, if any.
+// It returns false if the string does not end with a color code.
+func CutTrailing(s string) (string, Token, bool) {
+	// it runs on every player name, so it only looks at the end instead of tokenizing
+	n := len(s)
+	if n < 2 || s[n-2] != '^' || s[n-1] < '0' || s[n-1] > '9' {
+		return s, Token{}, false
+	}
+	// ^^ is an escaped caret: the code is a color only after an odd number of carets
+	carets := 0
+	for i := n - 2; i >= 0 && s[i] == '^'; i-- {
+		carets++
+	}
+	if carets%2 == 0 {
+		return s, Token{}, false
+	}
+	return s[:n-2], Token{Raw: s[n-2:], Color: int(s[n-1] - '0')}, true
+}
+
+// ANSIReset is the ANSI escape code the server prints at the end of colored lines.
+const ANSIReset = "\u001B[0m"
+
+var ansiToWarsow = map[string]string{
+	"\u001B[30m":       "^0", // Black
+	"\u001B[31m":       "^1", // Red
+	"\u001B[32m":       "^2", // Green
+	"\u001B[33m":       "^3", // Yellow
+	"\u001B[34m":       "^4", // Blue
+	"\u001B[36m":       "^5", // Cyan
+	"\u001B[35m":       "^6", // Purple
+	"\u001B[37m":       "^7", // White
+	"\u001B[38;5;208m": "^8", // Orange (approximation)
+	"\u001B[90m":       "^9", // Gray
+	"\u001B[0m":        "^7", // Reset (white)
+}
+
+var warsowToANSI = [10]string{
+	"\u001B[30m",
+	"\u001B[31m",
+	"\u001B[32m",
+	"\u001B[33m",
+	"\u001B[34m",
+	"\u001B[36m",
+	"\u001B[35m",
+	"\u001B[37m",
+	"\u001B[38;5;208m",
+	"\u001B[90m",
+}
+
+var warsowToHTML = [10]string{
+	"#000000",
+	"#ff0000",
+	"#00ff00",
+	"#ffff00",
+	"#0000ff",
+	"#00ffff",
+	"#ff00ff",
+	"#ffffff",
+	"#ff8000",
+	"#808080",
+}
+
+var ansiRegex = regexp.MustCompile(`\x1B\[[0-9;]*m`)
+
+// FromANSI converts the ANSI escape codes printed by the server into Warsow color codes.
+// Unknown ANSI codes are removed.
+func FromANSI(input string) string {
+	if !strings.Contains(input, "\x1b[") {
+		return input
+	}
+	return ansiRegex.ReplaceAllStringFunc(input, func(match string) string {
+		if warsowCode, exists := ansiToWarsow[match]; exists {
+			return warsowCode
+		}
+		return "" // Remove unknown ANSI codes
+	})
+}
+
+// ToANSI converts a Warsow colored string into ANSI escape codes, ending with a reset.
+func ToANSI(s string) string {
+	sb := strings.Builder{}
+	for _, t := range Tokenize(s) {
+		if t.IsColor() {
+			sb.WriteString(warsowToANSI[t.Color])
+		} else {
+			sb.WriteString(t.Text)
+		}
+	}
+	sb.WriteString(ANSIReset)
+	return sb.String()
+}
+
+// ToHTML converts a Warsow colored string into escaped HTML, each colored part is a span with an inline color.
+func ToHTML(s string) string {
+	sb := strings.Builder{}
+	open := false
+	for _, t := range Tokenize(s) {
+		if !t.IsColor() {
+			sb.WriteString(html.EscapeString(t.Text))
+			continue
+		}
+		if open {
+			sb.WriteString("</span>")
+		}
+		sb.WriteString(`<span style="color:`)
+		sb.WriteString(warsowToHTML[t.Color])
+		sb.WriteString(`">`)
+		open = true
+	}
+	if open {
+		sb.WriteString("</span>")
+	}
+	return sb.String()
+}
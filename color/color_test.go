@@ -0,0 +1,141 @@
+package color
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestTokenize(t *testing.T) {
+	tests := []struct {
+		name  string
+		input string
+		want  []Token
+	}{
+		{"plain text", "Sid", []Token{{Raw: "Sid", Text: "Sid", Color: -1}}},
+		{"colors", "^4Su^7tat", []Token{
+			{Raw: "^4", Color: 4},
+			{Raw: "Su", Text: "Su", Color: -1},
+			{Raw: "^7", Color: 7},
+			{Raw: "tat", Text: "tat", Color: -1},
+		}},
+		{"digit after a code", "k^99", []Token{
+			{Raw: "k", Text: "k", Color: -1},
+			{Raw: "^9", Color: 9},
+			{Raw: "9", Text: "9", Color: -1},
+		}},
+		{"escaped caret", "k^^7", []Token{{Raw: "k^^7", Text: "k^7", Color: -1}}},
+		{"escaped caret then a code", "^^^1k", []Token{
+			{Raw: "^^", Text: "^", Color: -1},
+			{Raw: "^1", Color: 1},
+			{Raw: "k", Text: "k", Color: -1},
+		}},
+		{"trailing caret", "k^", []Token{{Raw: "k^", Text: "k^", Color: -1}}},
+		{"caret before a letter", "^ak", []Token{{Raw: "^ak", Text: "^ak", Color: -1}}},
+		{"empty", "", []Token{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := Tokenize(tt.input); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("Tokenize(%q) = %+v, want %+v", tt.input, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestStrip(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"^4Su^7tat^7", "Sutat"},
+		{"k^99", "k9"},
+		{"k^^7", "k^7"},
+		{"^^^7", "^"},
+		{"k^", "k^"},
+		{"^7", ""},
+	}
+	for _, tt := range tests {
+		if got := Strip(tt.input); got != tt.want {
+			t.Errorf("Strip(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestCutTrailing(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+		ok    bool
+	}{
+		{"Sid^7", "Sid", true},
+		{"k^99", "k^99", false},
+		{"k^^7", "k^^7", false},
+		{"k^^^7", "k^^", true},
+		{"k^", "k^", false},
+		{"Sid", "Sid", false},
+	}
+	for _, tt := range tests {
+		if got, _, ok := CutTrailing(tt.input); got != tt.want || ok != tt.ok {
+			t.Errorf("CutTrailing(%q) = %q, %t, want %q, %t", tt.input, got, ok, tt.want, tt.ok)
+		}
+	}
+}
+
+func TestToANSI(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Sid", "Sid" + ANSIReset},
+		{"^1Sid^7", "\u001B[31mSid\u001B[37m" + ANSIReset},
+		{"k^^1", "k^1" + ANSIReset},
+		{"k^", "k^" + ANSIReset},
+	}
+	for _, tt := range tests {
+		if got := ToANSI(tt.input); got != tt.want {
+			t.Errorf("ToANSI(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
+
+func TestFromANSI(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Sid", "Sid"},
+		{"\u001B[31mSid\u001B[0m", "^1Sid^7"},
+		{"\u001B[38;5;208mSid", "^8Sid"},
+		{"\u001B[1mSid", "Sid"},
+	}
+	for _, tt := range tests {
+		if got := FromANSI(tt.input); got != tt.want {
+			t.Errorf("FromANSI(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+	for i := range 10 {
+		code := "^" + string(rune('0'+i))
+		if got := FromANSI(ToANSI(code + "k")); got != code+"k^7" {
+			t.Errorf("FromANSI(ToANSI(%q)) = %q, want %q", code+"k", got, code+"k^7")
+		}
+	}
+}
+
+func TestToHTML(t *testing.T) {
+	tests := []struct {
+		input string
+		want  string
+	}{
+		{"Sid", "Sid"},
+		{"^1Si^7d", `<span style="color:#ff0000">Si</span><span style="color:#ffffff">d</span>`},
+		{"<b>&\"", "&lt;b&gt;&amp;&#34;"},
+		{"^1<script>", `<span style="color:#ff0000">&lt;script&gt;</span>`},
+		{"k^^1", "k^1"},
+		{"k^", "k^"},
+	}
+	for _, tt := range tests {
+		if got := ToHTML(tt.input); got != tt.want {
+			t.Errorf("ToHTML(%q) = %q, want %q", tt.input, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,52 @@
+package warsowlog
+
+import (
+	"context"
+)
+
+// Decision is how the parser understood a line, see Parser.OnDecision.
+type Decision struct {
+	Line string `json:"line"`
+	// Rule is the rule of the parser that matched the line, unmatched if none did
+	Rule string `json:"rule"`
+	// Fields are the values the rule extracted from the line, as printed by the server
+	Fields map[string]string `json:"fields,omitempty"`
+	// Names are the player names of the line once sanitized, by name as printed by the server
+	Names map[string]string `json:"names,omitempty"`
+	// MatchID is the match the line belongs to, see Game.MatchID
+	MatchID string `json:"match_id"`
+}
+
+// OnDecision registers a handler called with the decision taken for every line, see OnEvent.
+// Recording decisions costs a little on every line, the parser only does it when a handler is registered.
+func (p *Parser) OnDecision(handler func(context.Context, Decision)) {
+	p.handlers.decision = append(p.handlers.decision, handler)
+}
+
+// decide records the rule matching the line being parsed and the values it extracted, as key value pairs.
+// Empty values are left out, like the killer of an environmental death.
+func (p *Parser) decide(rule string, fields ...string) {
+	if p.decision == nil {
+		return
+	}
+	p.decision.Rule = rule
+	if len(fields) > 0 {
+		p.decision.Fields = make(map[string]string, len(fields)/2)
+		for i := 0; i+1 < len(fields); i += 2 {
+			if fields[i+1] != "" {
+				p.decision.Fields[fields[i]] = fields[i+1]
+			}
+		}
+	}
+}
+
+// decideName records a player name of the line being parsed once sanitized.
+func (p *Parser) decideName(name, sanitized string) {
+	if p.decision == nil {
+		return
+	}
+	if p.decision.Names == nil {
+		p.decision.Names = make(map[string]string)
+	}
+	p.decision.Names[name] = sanitized
+}
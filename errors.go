@@ -0,0 +1,47 @@
+package warsowlog
+
+import (
+	"context"
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrUnmatchedLine is reported for lines that no rule of the parser recognizes
+	ErrUnmatchedLine = errors.New("unmatched line")
+	// ErrAmbiguousName is reported when a player name could designate several players of the roster
+	ErrAmbiguousName = errors.New("ambiguous player name")
+	// ErrStateConflict is reported when a line does not fit the known state of the game,
+	// like the end of a match that never started
+	ErrStateConflict = errors.New("state conflict")
+)
+
+// ParseError is an issue met while parsing a line, it wraps one of the Err values of the package:
+//
+//	errors.Is(err, warsowlog.ErrUnmatchedLine)
+//
+// Those issues never stop the parser, they measure how well it understands the logs.
+type ParseError struct {
+	Line string
+	Err  error
+}
+
+func (e *ParseError) Error() string {
+	return fmt.Sprintf("%v in %q", e.Err, e.Line)
+}
+
+func (e *ParseError) Unwrap() error {
+	return e.Err
+}
+
+// OnError registers a handler called with every ParseError, see OnEvent.
+func (p *Parser) OnError(handler func(context.Context, error)) {
+	p.handlers.err = append(p.handlers.err, handler)
+}
+
+// fail reports an issue with the line being parsed to the OnError handlers.
+func (p *Parser) fail(err error) {
+	if len(p.handlers.err) > 0 {
+		p.queue(&ParseError{Line: p.line, Err: err})
+	}
+}
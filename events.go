@@ -0,0 +1,116 @@
+package warsowlog
+
+import (
+	"context"
+	"log/slog"
+)
+
+// Event is a record emitted by the parser, see Parser.OnEvent.
+type Event struct {
+	Level slog.Level
+	// Kind is the kind attribute of the record, empty for lines without one
+	Kind string
+	// Message is the console line the record comes from
+	Message string
+	Attrs   []slog.Attr
+	// MatchID is the match the record belongs to, see Game.MatchID
+	MatchID string
+}
+
+// FragEvent is a frag of a player, see Parser.OnFrag.
+type FragEvent struct {
+	MatchID string
+	// Phase is the state of the match when the frag happened
+	Phase  MatchState
+	Victim string
+	// Killer is the victim for suicides and empty for environmental deaths
+	Killer   string
+	Weapon   string
+	TeamKill bool
+	// Dropped is true for bot vs bot frags that are not counted, see BotsDropFrags
+	Dropped bool
+}
+
+// MatchEndEvent is a full match that ended, with the content of its summary, see Parser.OnMatchEnd.
+type MatchEndEvent struct {
+	Game GameSnapshot
+	// Result is only set if HasResult is true, see ComputeResult
+	Result     Result
+	HasResult  bool
+	Unranked   bool
+	Scoreboard []ScoreboardRow
+}
+
+// handlers are the functions registered on a parser.
+type handlers struct {
+	event    []func(context.Context, Event)
+	frag     []func(context.Context, FragEvent)
+	matchEnd []func(context.Context, MatchEndEvent)
+	err      []func(context.Context, error)
+	decision []func(context.Context, Decision)
+}
+
+// OnEvent registers a handler called with every record, after the slog handler.
+// Handlers are called outside of the game commands so they can take snapshots,
+// they must be registered before the first line is parsed.
+func (p *Parser) OnEvent(handler func(context.Context, Event)) {
+	p.handlers.event = append(p.handlers.event, handler)
+}
+
+// OnFrag registers a handler called with every frag, see OnEvent.
+func (p *Parser) OnFrag(handler func(context.Context, FragEvent)) {
+	p.handlers.frag = append(p.handlers.frag, handler)
+}
+
+// OnMatchEnd registers a handler called when a full match ends, once its summary is emitted, see OnEvent.
+func (p *Parser) OnMatchEnd(handler func(context.Context, MatchEndEvent)) {
+	p.handlers.matchEnd = append(p.handlers.matchEnd, handler)
+}
+
+// queue keeps a typed event until the current command is over, see dispatch.
+func (p *Parser) queue(event any) {
+	p.queued = append(p.queued, event)
+}
+
+// dispatch calls the handlers of the queued events, it must not be called within a command.
+func (p *Parser) dispatch(ctx context.Context) {
+	queued := p.queued
+	p.queued = nil
+	for _, event := range queued {
+		switch e := event.(type) {
+		case FragEvent:
+			for _, handler := range p.handlers.frag {
+				handler(ctx, e)
+			}
+		case MatchEndEvent:
+			for _, handler := range p.handlers.matchEnd {
+				handler(ctx, e)
+			}
+		case error:
+			for _, handler := range p.handlers.err {
+				handler(ctx, e)
+			}
+		case Decision:
+			for _, handler := range p.handlers.decision {
+				handler(ctx, e)
+			}
+		}
+	}
+}
+
+// notify calls the OnEvent handlers with the record.
+func (p *Parser) notify(ctx context.Context, r record) {
+	if len(p.handlers.event) == 0 {
+		return
+	}
+	event := Event{Level: r.level, Message: r.msg, Attrs: r.attrs, MatchID: r.matchID}
+	for _, attr := range r.attrs {
+		if attr.Key == "kind" {
+			event.Kind = attr.Value.String()
+			break
+		}
+	}
+	for _, handler := range p.handlers.event {
+		handler(ctx, event)
+	}
+}
@@ -0,0 +1,196 @@
+package warsowlog
+
+import (
+	"errors"
+	"fmt"
+	"go/ast"
+	"go/parser"
+	"go/token"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/samber/lo"
+)
+
+// SummaryField is a user defined field computed for each player of the end of game summary.
+// The expression uses Go syntax on numbers, for example a league points formula:
+//
+//	points=winner*3 + draw + frags/10
+type SummaryField struct {
+	Name string
+	expr ast.Expr
+}
+
+// summaryVariables are the names usable in a summary field expression, see computeFields.
+var summaryVariables = []string{"total", "frags", "suicides", "deaths", "kd", "net", "rank", "players", "winner", "draw"}
+
+// summaryFunctions are the functions usable in a summary field expression.
+var summaryFunctions = map[string]func(args ...float64) float64{
+	"min": func(args ...float64) float64 { return reduce(args, math.Min) },
+	"max": func(args ...float64) float64 { return reduce(args, math.Max) },
+	"abs": func(args ...float64) float64 { return math.Abs(args[0]) },
+}
+
+func reduce(args []float64, fn func(a, b float64) float64) float64 {
+	acc := args[0]
+	for _, v := range args[1:] {
+		acc = fn(acc, v)
+	}
+	return acc
+}
+
+// ParseSummaryField parses a "name=expression" definition.
+// Unknown variables and functions are reported here so a typo does not wait the end of a game to show up.
+func ParseSummaryField(definition string) (SummaryField, error) {
+	name, source, ok := strings.Cut(definition, "=")
+	name = strings.TrimSpace(name)
+	if !ok || name == "" {
+		return SummaryField{}, fmt.Errorf("summary field %q must look like name=expression", definition)
+	}
+	expr, err := parser.ParseExpr(source)
+	if err != nil {
+		return SummaryField{}, fmt.Errorf("summary field %q: %w", name, err)
+	}
+	if err := validate(expr); err != nil {
+		return SummaryField{}, fmt.Errorf("summary field %q: %w", name, err)
+	}
+	return SummaryField{Name: name, expr: expr}, nil
+}
+
+// validate checks the expression only uses what eval supports.
+func validate(expr ast.Expr) error {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.INT && e.Kind != token.FLOAT {
+			return fmt.Errorf("unsupported literal %s", e.Value)
+		}
+	case *ast.Ident:
+		if !lo.Contains(summaryVariables, e.Name) {
+			return fmt.Errorf("unknown variable %q (expected one of %s)", e.Name, strings.Join(summaryVariables, ", "))
+		}
+	case *ast.ParenExpr:
+		return validate(e.X)
+	case *ast.UnaryExpr:
+		return validate(e.X)
+	case *ast.BinaryExpr:
+		if err := validate(e.X); err != nil {
+			return err
+		}
+		return validate(e.Y)
+	case *ast.CallExpr:
+		fn, ok := e.Fun.(*ast.Ident)
+		if !ok || summaryFunctions[fn.Name] == nil {
+			return fmt.Errorf("unknown function (expected min, max or abs)")
+		}
+		if len(e.Args) == 0 {
+			return fmt.Errorf("%s needs at least one argument", fn.Name)
+		}
+		for _, arg := range e.Args {
+			if err := validate(arg); err != nil {
+				return err
+			}
+		}
+	default:
+		return fmt.Errorf("unsupported expression %T", expr)
+	}
+	return nil
+}
+
+// errDivisionByZero is returned instead of a made up value, guard the divisor with max(x, 1) to avoid it.
+var errDivisionByZero = errors.New("division by zero")
+
+// Eval computes the field with the given variables, booleans are 1 (true) or 0 (false).
+func (f SummaryField) Eval(vars map[string]float64) (float64, error) {
+	return eval(f.expr, vars)
+}
+
+func eval(expr ast.Expr, vars map[string]float64) (float64, error) {
+	switch e := expr.(type) {
+	case *ast.BasicLit:
+		if e.Kind != token.INT && e.Kind != token.FLOAT {
+			return 0, fmt.Errorf("unsupported literal %s", e.Value)
+		}
+		return strconv.ParseFloat(e.Value, 64)
+	case *ast.Ident:
+		return vars[e.Name], nil
+	case *ast.ParenExpr:
+		return eval(e.X, vars)
+	case *ast.CallExpr:
+		args := make([]float64, 0, len(e.Args))
+		for _, arg := range e.Args {
+			v, err := eval(arg, vars)
+			if err != nil {
+				return 0, err
+			}
+			args = append(args, v)
+		}
+		return summaryFunctions[e.Fun.(*ast.Ident).Name](args...), nil
+	case *ast.UnaryExpr:
+		x, err := eval(e.X, vars)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case token.SUB:
+			return -x, nil
+		case token.ADD:
+			return x, nil
+		case token.NOT:
+			return boolToFloat(x == 0), nil
+		}
+		return 0, fmt.Errorf("unsupported operator %s", e.Op)
+	case *ast.BinaryExpr:
+		x, err := eval(e.X, vars)
+		if err != nil {
+			return 0, err
+		}
+		y, err := eval(e.Y, vars)
+		if err != nil {
+			return 0, err
+		}
+		switch e.Op {
+		case token.ADD:
+			return x + y, nil
+		case token.SUB:
+			return x - y, nil
+		case token.MUL:
+			return x * y, nil
+		case token.QUO:
+			if y == 0 {
+				return 0, errDivisionByZero
+			}
+			return x / y, nil
+		case token.REM:
+			if y == 0 {
+				return 0, errDivisionByZero
+			}
+			return math.Mod(x, y), nil
+		case token.EQL:
+			return boolToFloat(x == y), nil
+		case token.NEQ:
+			return boolToFloat(x != y), nil
+		case token.LSS:
+			return boolToFloat(x < y), nil
+		case token.LEQ:
+			return boolToFloat(x <= y), nil
+		case token.GTR:
+			return boolToFloat(x > y), nil
+		case token.GEQ:
+			return boolToFloat(x >= y), nil
+		case token.LAND:
+			return boolToFloat(x != 0 && y != 0), nil
+		case token.LOR:
+			return boolToFloat(x != 0 || y != 0), nil
+		}
+		return 0, fmt.Errorf("unsupported operator %s", e.Op)
+	}
+	return 0, fmt.Errorf("unsupported expression %T", expr)
+}
+
+func boolToFloat(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
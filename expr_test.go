@@ -0,0 +1,112 @@
+package warsowlog
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestParseSummaryField(t *testing.T) {
+	tests := []struct {
+		definition string
+		err        string
+	}{
+		{"points=winner*3 + draw + frags/10", ""},
+		{"ratio=frags/max(deaths, 1)", ""},
+		{" spaced = -abs(net) ", ""},
+		{"points", "must look like name=expression"},
+		{"=frags", "must look like name=expression"},
+		{"points=frags +", "expected operand"},
+		{"points=kills*2", `unknown variable "kills"`},
+		{"points=sqrt(frags)", "unknown function"},
+		{"points=max()", "max needs at least one argument"},
+		{`points="frags"`, "unsupported literal"},
+		{"points=frags[0]", "unsupported expression"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.definition, func(t *testing.T) {
+			_, err := ParseSummaryField(tt.definition)
+			if tt.err == "" {
+				if err != nil {
+					t.Errorf("unexpected error: %v", err)
+				}
+				return
+			}
+			if err == nil || !strings.Contains(err.Error(), tt.err) {
+				t.Errorf("expected an error containing %q, got %v", tt.err, err)
+			}
+		})
+	}
+}
+
+func TestSummaryFieldEval(t *testing.T) {
+	vars := map[string]float64{"frags": 12, "deaths": 4, "winner": 1, "draw": 0, "net": -3}
+	tests := []struct {
+		expression string
+		want       float64
+		err        string
+	}{
+		{"winner*3 + draw + frags/10", 4.2, ""},
+		{"frags/deaths", 3, ""},
+		{"frags % 5", 2, ""},
+		{"-frags + +deaths", -8, ""},
+		{"min(frags, deaths, 7)", 4, ""},
+		{"max(frags, deaths)", 12, ""},
+		{"abs(net)", 3, ""},
+		{"(frags >= 10) + (deaths < 4) + (frags == 12) + (deaths != 4) + (frags > 12) + (deaths <= 4)", 3, ""},
+		{"winner && !draw", 1, ""},
+		{"draw || 0", 0, ""},
+		{"frags / draw", 0, "division by zero"},
+		{"frags % draw", 0, "division by zero"},
+		{"1 + max(frags/draw, 1)", 0, "division by zero"},
+		{"frags &^ deaths", 0, "unsupported operator"},
+		{"^frags", 0, "unsupported operator"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.expression, func(t *testing.T) {
+			field, err := ParseSummaryField("f=" + tt.expression)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got, err := field.Eval(vars)
+			if tt.err != "" {
+				if err == nil || !strings.Contains(err.Error(), tt.err) {
+					t.Errorf("expected an error containing %q, got %v (%v)", tt.err, err, got)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if diff := got - tt.want; diff > 1e-9 || diff < -1e-9 {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestComputeFields(t *testing.T) {
+	points, _ := ParseSummaryField("points=winner*3 + draw")
+	ratio, _ := ParseSummaryField("ratio=frags/deaths")
+	p := newTestParser(Options{SummaryFields: []SummaryField{points, ratio}})
+	g := teamGame("ca", map[string]string{"A": "ALPHA", "B": "ALPHA", "C": "BETA"})
+	g.EndRound(TeamAlpha)
+	scoring := ScoringFor(g.GameType)
+	result, _ := ComputeResult(g, scoring, g.Playing())
+
+	computed := make(map[string]map[string]string)
+	for _, player := range p.computeFields(g, scoring, g.Playing(), result).Value.Group() {
+		fields := make(map[string]string)
+		for _, field := range player.Value.Group() {
+			fields[field.Key] = field.Value.String()
+		}
+		computed[player.Key] = fields
+	}
+	for name, want := range map[string]string{"A": "3", "B": "3", "C": "0"} {
+		if got := computed[name]["points"]; got != want {
+			t.Errorf("points of %s = %s, want %s", name, got, want)
+		}
+		if got := computed[name]["ratio_error"]; got != "division by zero" {
+			t.Errorf("ratio of %s should be reported as an error, got %v", name, computed[name])
+		}
+	}
+}
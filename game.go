@@ -1,63 +1,580 @@
-package main
+package warsowlog
 
 import (
+	"maps"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/samber/lo"
 )
 
+// Game is not safe for concurrent use by itself:
+// the parse loop mutates it through Apply while other goroutines only read it through Snapshot.
 type Game struct {
-	// false if the game is not registered from the beginning
-	// it happens when we bound the logs of an already started game/server
-	hasStarted bool
-	hasEnded   bool
-	GameType   string
-	startAt    time.Time
-	players    map[string]*Player
+	mu       sync.RWMutex
+	GameType string
+	// ID identifies the game, a game is a map session
+	ID string
+	// Map is the name of the map the game is played on, empty if unknown
+	Map string
+	// match is the current match, the last one of matches
+	match *Match
+	// matches are the matches played on the map so far, in order
+	matches []*Match
+	players map[string]*Player
+	// ipNames stores the distinct player names seen for each IP
+	ipNames map[string]map[string]bool
+	// rounds counts the rounds played so far in round-based gametypes
+	rounds int
+	// roundEnded is true between the end of a round and the start of the next one
+	roundEnded bool
+	// roundWins counts the rounds won by each team or player
+	roundWins map[string]int
+	// roundFrags counts the frags of each player, per round
+	roundFrags map[int]map[string]int
+	// timeoutsAt stores when the recent timeouts happened, see RecentTimeouts
+	timeoutsAt []time.Time
+	// evictAfter is how long a disconnected player without score is kept around
+	evictAfter time.Duration
+	// maxPlayers caps the number of players kept in memory (0 means no limit)
+	maxPlayers int
 }
 
+// gameCount numbers the games of the process, clocks are too coarse to tell apart games created
+// back to back (the WebAssembly clock has a millisecond resolution).
+var gameCount atomic.Uint64
+
 func NewGame(gameType string) *Game {
-	return &Game{
+	g := &Game{
+		// the start time tells apart the games of different runs, the count the games of this one
+		ID:         strconv.FormatInt(time.Now().UnixNano(), 36) + "." + strconv.FormatUint(gameCount.Add(1), 36),
 		players:    make(map[string]*Player),
-		hasStarted: false,
+		ipNames:    make(map[string]map[string]bool),
+		roundWins:  make(map[string]int),
+		roundFrags: make(map[int]map[string]int),
 		GameType:   gameType,
 	}
+	g.addMatch()
+	return g
+}
+
+// MatchState is the phase of a match.
+type MatchState string
+
+const (
+	// MatchUnknown is the state of a match whose beginning was not seen,
+	// it happens when we bound the logs of an already started game/server
+	MatchUnknown   MatchState = "unknown"
+	MatchWarmup    MatchState = "warmup"
+	MatchCountdown MatchState = "countdown"
+	MatchLive      MatchState = "live"
+	MatchPost      MatchState = "post_match"
+)
+
+// Match is one actual match of a game, from its warmup to the end of match scoreboard.
+// A game holds several matches when the map is restarted or a match is played again on the same map.
+type Match struct {
+	// ID identifies the match, it is prefixed by the ID of its game
+	ID    string
+	State MatchState
+	// StartAt is zero if the match did not go live
+	StartAt time.Time
+	EndAt   time.Time
+	// pausedAt is zero while the match is not paused
+	pausedAt time.Time
+	// Paused is the total time the match was paused, it does not count in Duration
+	Paused time.Duration
+	// OvertimeAt is zero if the match did not go to overtime or sudden death
+	OvertimeAt time.Time
+	// FirstBlood is the player who made the first frag of the match once live, empty if unknown
+	FirstBlood string
+	// Scores are the scores of the players when the match ended: playerName -> victimName -> score
+	Scores map[string]map[string]int
+	// GameType is the gametype the match is played in, a vote can change it between two matches of a game
+	GameType string
+	// Map is only set on the matches returned by ParseAll, see Game for the current one
+	Map string
+	// Timeline holds the events of the match in order, it is only set by ParseAll
+	Timeline []Event
+}
+
+// Duration returns the time played between the start and the end of the match, pauses excluded,
+// or zero if the start or the end is unknown.
+func (m *Match) Duration() time.Duration {
+	if m.StartAt.IsZero() || m.State != MatchPost {
+		return 0
+	}
+	return m.EndAt.Sub(m.StartAt) - m.Paused
+}
+
+// addMatch begins a new match in the game, the scores of the previous one are reset.
+func (g *Game) addMatch() {
+	g.match = &Match{ID: g.ID + "-" + strconv.Itoa(len(g.matches)), State: MatchWarmup, GameType: g.GameType}
+	g.matches = append(g.matches, g.match)
+	g.resetScores()
+}
+
+// resetScores forgets the scores and rounds of the current match.
+func (g *Game) resetScores() {
+	g.rounds = 0
+	g.roundEnded = false
+	g.roundWins = make(map[string]int)
+	g.roundFrags = make(map[int]map[string]int)
+	for _, p := range g.players {
+		p.ResetScores()
+	}
+}
+
+// Matches returns the matches played on the map so far, the current one last.
+func (g *Game) Matches() []*Match {
+	return g.matches
+}
+
+// WithLimits sets the memory bounds of the game, see Evict.
+func (g *Game) WithLimits(evictAfter time.Duration, maxPlayers int) *Game {
+	g.evictAfter = evictAfter
+	g.maxPlayers = maxPlayers
+	return g
+}
+
+// Apply runs the command with exclusive access to the game.
+// Every mutation of the game or of its players must happen inside a command.
+func (g *Game) Apply(command func(g *Game)) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	command(g)
+}
+
+// GameSnapshot is a copy of the game state, safe to read from any goroutine.
+type GameSnapshot struct {
+	GameType   string
+	Map        string
+	MatchID    string
+	Matches    int
+	State      MatchState
+	HasStarted bool
+	HasEnded   bool
+	StartAt    time.Time
+	Players    []PlayerSnapshot
+}
+
+// PlayerSnapshot is a copy of the player state, safe to read from any goroutine.
+type PlayerSnapshot struct {
+	Name        string
+	TextName    string
+	IP          string
+	Connected   bool
+	IsBot       bool
+	Team        string
+	TeamDisplay string
+	Spectator   bool
+	TeamKills   int
+	Captures    int
+	BestTime    time.Duration
+	Reconnects  int
+	IPChanged   bool
+	Disconnects int
+	Timeouts    int
+	Deaths      int
+	BestSpree   int
+	Multikills  map[string]int
+	Weapons     map[string]int
+	Scores      map[string]int
+}
+
+// Snapshot returns a copy of the game that does not race with the commands applied afterward.
+func (g *Game) Snapshot() GameSnapshot {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+	return g.snapshot()
+}
+
+// snapshot is Snapshot for callers already holding the lock, like commands.
+func (g *Game) snapshot() GameSnapshot {
+	return GameSnapshot{
+		GameType:   g.GameType,
+		Map:        g.Map,
+		MatchID:    g.match.ID,
+		Matches:    len(g.matches),
+		State:      g.match.State,
+		HasStarted: g.HasStarted(),
+		HasEnded:   g.HasEnded(),
+		StartAt:    g.match.StartAt,
+		Players: lo.MapToSlice(g.players, func(_ string, p *Player) PlayerSnapshot {
+			return p.Snapshot()
+		}),
+	}
 }
 
 func (g *Game) Players() []*Player {
 	return lo.Values(g.players)
 }
 
+// Playing returns the players that take part in the current match, see Player.IsPlaying.
+func (g *Game) Playing() []*Player {
+	return lo.Filter(lo.Values(g.players), func(p *Player, _ int) bool { return p.IsPlaying() })
+}
+
+// State returns the state of the current match.
+func (g *Game) State() MatchState {
+	return g.match.State
+}
+
+// SetState moves the current match to the state and returns the previous one.
+// Leaving the post match state begins a new match, going live starts the clock
+// and forgets the warmup frags, and the post match state keeps the scores of the players.
+func (g *Game) SetState(state MatchState) MatchState {
+	previous := g.match.State
+	if previous == MatchPost && state != MatchPost {
+		g.addMatch()
+	}
+	g.match.State = state
+	switch state {
+	case MatchLive:
+		g.match.StartAt = time.Now()
+		if previous == MatchWarmup || previous == MatchCountdown {
+			g.resetScores()
+		}
+	case MatchPost:
+		g.match.EndAt = time.Now()
+		if !g.match.pausedAt.IsZero() {
+			// a match can end during a pause when it is abandoned
+			g.Resume(g.match.EndAt)
+		}
+		g.match.Scores = make(map[string]map[string]int, len(g.players))
+		for name, p := range g.players {
+			g.match.Scores[name] = maps.Clone(p.Scores)
+		}
+	}
+	return previous
+}
+
+// Start starts the current match, or a new one if the current match already ended.
 func (g *Game) Start() {
-	g.hasStarted = true
-	g.startAt = time.Now()
+	g.SetState(MatchLive)
 }
 
+// End ends the current match and keeps the scores of its players.
 func (g *Game) End() {
-	g.hasEnded = true
+	g.SetState(MatchPost)
+}
+
+// StartOvertime records the overtime of the current match, it returns false if it already started.
+// Some gametypes announce each overtime period, only the first one counts.
+func (g *Game) StartOvertime(now time.Time) bool {
+	if !g.match.OvertimeAt.IsZero() {
+		return false
+	}
+	g.match.OvertimeAt = now
+	return true
+}
+
+// Overtime returns the time played in overtime by the current match, zero if it did not end.
+func (g *Game) Overtime() time.Duration {
+	if g.match.OvertimeAt.IsZero() || !g.HasEnded() {
+		return 0
+	}
+	return g.match.EndAt.Sub(g.match.OvertimeAt)
+}
+
+// RecordFirstBlood gives the first blood of the current match to the player,
+// it returns false if the match is not live or if the first blood was already made.
+func (g *Game) RecordFirstBlood(name string) bool {
+	if g.match.State != MatchLive || g.match.FirstBlood != "" {
+		return false
+	}
+	g.match.FirstBlood = name
+	return true
+}
+
+// Pause pauses the current match, it returns false if the match is already paused.
+func (g *Game) Pause(now time.Time) bool {
+	if !g.match.pausedAt.IsZero() {
+		return false
+	}
+	g.match.pausedAt = now
+	return true
+}
+
+// Resume resumes the current match and returns how long it was paused, zero if it was not.
+func (g *Game) Resume(now time.Time) time.Duration {
+	if g.match.pausedAt.IsZero() {
+		return 0
+	}
+	paused := now.Sub(g.match.pausedAt)
+	g.match.Paused += paused
+	g.match.pausedAt = time.Time{}
+	return paused
+}
+
+// HasStarted returns true if the current match went live.
+func (g *Game) HasStarted() bool {
+	return !g.match.StartAt.IsZero()
+}
+
+// HasEnded returns true if the current match ended.
+func (g *Game) HasEnded() bool {
+	return g.match.State == MatchPost
+}
+
+// Duration returns the duration of the current match, or zero if its start or its end is unknown.
+func (g *Game) Duration() time.Duration {
+	return g.match.Duration()
 }
 
 func (g *Game) AddPlayer(name, ip string) *Player {
 	player, ok := g.players[name]
 	if !ok {
+		g.Evict(time.Now())
 		player = NewPlayer(name)
 		g.players[name] = player
 	}
 	player.connected = true
+	player.disconnectedAt = time.Time{}
 	if len(ip) > 0 {
 		player.IP = ip
 	}
 	return player
 }
 
+func (g *Game) HasPlayer(name string) bool {
+	_, ok := g.players[name]
+	return ok
+}
+
+// Connect registers a connection of the player from the given ip.
+// It returns true if the player was already known from a previous connection (a reconnect),
+// in that case the reconnect is counted and an IP change is flagged on the player.
+func (g *Game) Connect(name, ip string) (*Player, bool) {
+	player, ok := g.players[name]
+	reconnect := ok && (!player.connected || len(player.IP) > 0)
+	if reconnect {
+		player.Reconnects++
+		if len(player.IP) > 0 && player.IP != ip {
+			player.ipChanged = true
+		}
+	}
+	g.addIPName(ip, name)
+	return g.AddPlayer(name, ip), reconnect
+}
+
+func (g *Game) addIPName(ip, name string) {
+	if len(ip) == 0 {
+		return
+	}
+	names, ok := g.ipNames[ip]
+	if !ok {
+		names = make(map[string]bool)
+		g.ipNames[ip] = names
+	}
+	names[name] = true
+}
+
+// Rename moves the player to its new name, keeping its IP and its scores,
+// so a renaming player is not split in two. If the new name is already known, both players are merged.
+func (g *Game) Rename(oldName, newName string) *Player {
+	player, ok := g.players[oldName]
+	if !ok || oldName == newName {
+		return g.AddPlayer(newName, "")
+	}
+	delete(g.players, oldName)
+	if existing, ok := g.players[newName]; ok {
+		existing.merge(player)
+		player = existing
+	} else {
+		player.Name = newName
+		player.TextName = playerFlat(newName)
+		g.players[newName] = player
+	}
+	player.connected = true
+	player.disconnectedAt = time.Time{}
+
+	// the frags of the old name, suicides included, are counted under the new one
+	for _, p := range g.players {
+		if score, ok := p.Scores[oldName]; ok {
+			delete(p.Scores, oldName)
+			p.Scores[newName] += score
+		}
+	}
+	for _, frags := range g.roundFrags {
+		if n, ok := frags[oldName]; ok {
+			delete(frags, oldName)
+			frags[newName] += n
+		}
+	}
+	g.addIPName(player.IP, newName)
+	return player
+}
+
+// RecordTimeout registers a player timeout and returns the number of timeouts within the window,
+// older timeouts are forgotten.
+func (g *Game) RecordTimeout(now time.Time, window time.Duration) int {
+	recent := g.timeoutsAt[:0]
+	for _, at := range g.timeoutsAt {
+		if now.Sub(at) <= window {
+			recent = append(recent, at)
+		}
+	}
+	g.timeoutsAt = append(recent, now)
+	return len(g.timeoutsAt)
+}
+
+// ResetTimeouts forgets the recent timeouts, so an alert is not raised twice for the same ones.
+func (g *Game) ResetTimeouts() {
+	g.timeoutsAt = nil
+}
+
+// TeamScores returns the total score of each team, players without a real team and spectators are left out.
+func (g *Game) TeamScores() map[string]int {
+	scores := make(map[string]int)
+	for _, p := range g.players {
+		if isTeam(p.Team) && !p.Spectator {
+			scores[p.Team] += p.Total()
+		}
+	}
+	return scores
+}
+
+// WeaponFrags returns the frags of the players by weapon.
+func (g *Game) WeaponFrags(players []*Player) map[string]int {
+	frags := make(map[string]int)
+	for _, p := range players {
+		for weapon, n := range p.Weapons {
+			frags[weapon] += n
+		}
+	}
+	return frags
+}
+
+// ConnectedCount returns the number of players currently connected.
+func (g *Game) ConnectedCount() int {
+	count := 0
+	for _, p := range g.players {
+		if p.connected {
+			count++
+		}
+	}
+	return count
+}
+
+// NamesOf returns the distinct player names seen for the IP during the game.
+func (g *Game) NamesOf(ip string) []string {
+	names := make([]string, 0, len(g.ipNames[ip]))
+	for name := range g.ipNames[ip] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Evict forgets players that are disconnected since more than evictAfter and never played, see Player.hasPlayed.
+// If there are still more than maxPlayers players, the oldest disconnected ones that never played
+// are forgotten too, so a busy public server can not grow the game without bound.
+// Players that played are always kept since they are part of the game summary.
+func (g *Game) Evict(now time.Time) {
+	evictable := make([]*Player, 0)
+	for name, p := range g.players {
+		if p.connected || p.hasPlayed() {
+			continue
+		}
+		if g.evictAfter > 0 && now.Sub(p.disconnectedAt) > g.evictAfter {
+			delete(g.players, name)
+			continue
+		}
+		evictable = append(evictable, p)
+	}
+
+	if g.maxPlayers <= 0 || len(g.players) < g.maxPlayers {
+		return
+	}
+	sort.Slice(evictable, func(i, j int) bool {
+		return evictable[i].disconnectedAt.Before(evictable[j].disconnectedAt)
+	})
+	for _, p := range evictable {
+		if len(g.players) < g.maxPlayers {
+			return
+		}
+		delete(g.players, p.Name)
+	}
+}
+
+// MatchID identifies the current match of the game, it changes on each new match.
+func (g *Game) MatchID() string {
+	return g.match.ID
+}
+
+// Restart begins a new match on the same map: players are kept but their scores are reset.
+func (g *Game) Restart() {
+	g.addMatch()
+}
+
+// Round returns the number of the current round, starting at 1.
+func (g *Game) Round() int {
+	return g.rounds + 1
+}
+
+// StartRound begins a round, n is the round number printed by the server or 0 if unknown.
+// It is also called with 0 when the round is known to be under way, like on a bomb plant.
+func (g *Game) StartRound(n int) {
+	if n > 0 {
+		g.rounds = n - 1
+	}
+	g.roundEnded = false
+}
+
+// EndRound ends the current round, the next events belong to the next round.
+// The winner is a team or a player, empty if unknown.
+// Some gametypes print several end of round lines, the round is only ended once.
+// It returns the number of the round that ended.
+func (g *Game) EndRound(winner string) int {
+	if !g.roundEnded {
+		g.rounds++
+		g.roundEnded = true
+	}
+	if winner != "" {
+		g.roundWins[winner]++
+	}
+	return g.rounds
+}
+
+// RoundWins returns the number of rounds won by the team or the player.
+func (g *Game) RoundWins(winner string) int {
+	return g.roundWins[winner]
+}
+
+// Rounds returns the number of rounds played.
+func (g *Game) Rounds() int {
+	return g.rounds
+}
+
+// RecordRoundFrag counts a frag of the player in the current round.
+// A frag means the round is under way, for the logs without round start lines.
+func (g *Game) RecordRoundFrag(name string) {
+	g.roundEnded = false
+	round := g.Round()
+	frags, ok := g.roundFrags[round]
+	if !ok {
+		frags = make(map[string]int)
+		g.roundFrags[round] = frags
+	}
+	frags[name]++
+}
+
+// RoundFrags returns the frags of each player in the given round.
+func (g *Game) RoundFrags(round int) map[string]int {
+	return g.roundFrags[round]
+}
+
 func (g *Game) IsClean() bool {
-	return g.hasStarted && g.GameType != ""
+	return g.HasStarted() && g.GameType != ""
 }
 
 func (g *Game) IsFullGame() bool {
-	return g.IsClean() && g.hasEnded
+	return g.IsClean() && g.HasEnded()
 }
 
 func (g *Game) String() string {
@@ -91,20 +608,162 @@ type Player struct {
 	TextName  string
 	IP        string
 	connected bool
+	// disconnectedAt is zero while the player is connected
+	disconnectedAt time.Time
+	// Team is the team the player joined last, normalized by NormalizeTeam, empty if unknown
+	Team string
+	// TeamDisplay is the team as printed by the server, with its colors
+	TeamDisplay string
+	// Spectator is true while the player watches the game instead of playing
+	Spectator bool
+	// BestTime is the best race time of the player, zero if the player did not finish a race
+	BestTime time.Duration
+	// Captures counts the flags captured in CTF
+	Captures int
+	// TeamKills counts the frags of teammates
+	TeamKills int
+	// Reconnects counts the connections following the first one
+	Reconnects int
+	// ipChanged is true if the player reconnected from another IP at least once
+	ipChanged   bool
+	Disconnects int
+	Timeouts    int
+	// Deaths counts the deaths of the player, suicides and environmental deaths included
+	Deaths int
+	// BestSpree is the most frags the player made without dying
+	BestSpree int
+	// Multikills counts the multikills of the player by their final size: double, triple or mega
+	Multikills map[string]int
+	streak     streak
+	// Weapons counts the frags of the player by weapon, suicides aside
+	Weapons map[string]int
 	// playerName -> score
 	Scores map[string]int
 }
 
 func NewPlayer(name string) *Player {
 	return &Player{
-		Name:     name,
-		TextName: playerFlat(name),
-		Scores:   make(map[string]int),
+		Name:       name,
+		TextName:   playerFlat(name),
+		Multikills: make(map[string]int),
+		Weapons:    make(map[string]int),
+		Scores:     make(map[string]int),
+	}
+}
+
+// Snapshot returns a copy of the player, it must be called within a game command or snapshot.
+func (p *Player) Snapshot() PlayerSnapshot {
+	return PlayerSnapshot{
+		Name:        p.Name,
+		TextName:    p.TextName,
+		IP:          p.IP,
+		Connected:   p.connected,
+		IsBot:       p.IsBot(),
+		Team:        p.Team,
+		TeamDisplay: p.TeamDisplay,
+		Spectator:   p.Spectator,
+		TeamKills:   p.TeamKills,
+		Captures:    p.Captures,
+		BestTime:    p.BestTime,
+		Reconnects:  p.Reconnects,
+		IPChanged:   p.ipChanged,
+		Disconnects: p.Disconnects,
+		Timeouts:    p.Timeouts,
+		Deaths:      p.Deaths,
+		BestSpree:   p.BestSpree,
+		Multikills:  maps.Clone(p.Multikills),
+		Weapons:     maps.Clone(p.Weapons),
+		Scores:      maps.Clone(p.Scores),
 	}
 }
 
-func (p *Player) Disconnect() {
+// JoinTeam moves the player to the team as printed by the server, it returns the previous normalized team (empty if unknown).
+// Joining the spectator team makes the player a spectator, any other team makes them play.
+func (p *Player) JoinTeam(team string) string {
+	previous := p.Team
+	p.Team = NormalizeTeam(team)
+	p.TeamDisplay = team
+	p.Spectator = p.Team == TeamSpectator
+	return previous
+}
+
+// Spectate makes the player a spectator.
+func (p *Player) Spectate() {
+	p.Spectator = true
+}
+
+// IsPlaying returns false for spectators, unless they scored before joining the spectators.
+func (p *Player) IsPlaying() bool {
+	return !p.Spectator || p.HasScored()
+}
+
+// Disconnect marks the player as disconnected, the reason is empty if the server did not give one.
+// Timeouts are counted apart since they tell about the network quality of the player.
+func (p *Player) Disconnect(reason string) {
 	p.connected = false
+	p.disconnectedAt = time.Now()
+	p.Disconnects++
+	if isTimeout(reason) {
+		p.Timeouts++
+	}
+}
+
+func isTimeout(reason string) bool {
+	return strings.Contains(reason, "timed out")
+}
+
+// Total returns the sum of the scores of the player, suicides included.
+func (p *Player) Total() int {
+	total := 0
+	for _, v := range p.Scores {
+		total += v
+	}
+	return total
+}
+
+// Frags returns the number of frags of other players.
+func (p *Player) Frags() int {
+	frags := 0
+	for name, v := range p.Scores {
+		if name != p.Name {
+			frags += v
+		}
+	}
+	return frags
+}
+
+// Suicides returns the number of self kills.
+func (p *Player) Suicides() int {
+	return -p.Scores[p.Name]
+}
+
+// KD returns the frags of the player per death, the frags themselves if the player never died.
+func (p *Player) KD() float64 {
+	if p.Deaths == 0 {
+		return float64(p.Frags())
+	}
+	return float64(p.Frags()) / float64(p.Deaths)
+}
+
+// NetScore returns the frags of the player minus their deaths.
+func (p *Player) NetScore() int {
+	return p.Frags() - p.Deaths
+}
+
+// HasScored returns true if the player has at least one non zero score.
+func (p *Player) HasScored() bool {
+	for _, v := range p.Scores {
+		if v != 0 {
+			return true
+		}
+	}
+	return false
+}
+
+// hasPlayed returns true if the player did anything a summary or a ranking counts:
+// a score, a death, a team kill, a capture or a race time.
+func (p *Player) hasPlayed() bool {
+	return p.HasScored() || p.Deaths > 0 || p.TeamKills > 0 || p.Captures > 0 || p.BestTime > 0
 }
 
 func (p *Player) IsBot() bool {
@@ -149,11 +808,71 @@ func (p *Player) String() string {
 	return sb.String()
 }
 
-// TODO: second argument is the weapon
-func (p *Player) Frag(name string, _ string) {
+// RaceTime records a race finish time, it returns true if it is the best time of the player.
+func (p *Player) RaceTime(t time.Duration) bool {
+	if p.BestTime == 0 || t < p.BestTime {
+		p.BestTime = t
+		return true
+	}
+	return false
+}
+
+// merge adds the state of the other player, which is another name of the same human.
+func (p *Player) merge(other *Player) {
+	for name, score := range other.Scores {
+		p.Scores[name] += score
+	}
+	if p.IP == "" {
+		p.IP = other.IP
+	}
+	if p.Team == "" {
+		p.Team = other.Team
+		p.TeamDisplay = other.TeamDisplay
+	}
+	if other.BestTime > 0 && (p.BestTime == 0 || other.BestTime < p.BestTime) {
+		p.BestTime = other.BestTime
+	}
+	p.Captures += other.Captures
+	p.Deaths += other.Deaths
+	p.BestSpree = max(p.BestSpree, other.BestSpree)
+	for name, n := range other.Multikills {
+		p.Multikills[name] += n
+	}
+	for weapon, n := range other.Weapons {
+		p.Weapons[weapon] += n
+	}
+	p.TeamKills += other.TeamKills
+	p.Reconnects += other.Reconnects
+	p.ipChanged = p.ipChanged || other.ipChanged
+	p.Disconnects += other.Disconnects
+	p.Timeouts += other.Timeouts
+}
+
+// ResetScores forgets everything the player did during the match, but not who the player is.
+func (p *Player) ResetScores() {
+	p.Scores = make(map[string]int)
+	p.TeamKills = 0
+	p.Captures = 0
+	p.BestTime = 0
+	p.Deaths = 0
+	p.BestSpree = 0
+	p.Multikills = make(map[string]int)
+	p.Weapons = make(map[string]int)
+	p.streak = streak{}
+}
+
+// TeamKill counts a frag of a teammate, it costs a point.
+func (p *Player) TeamKill(name string) {
+	p.Scores[name]--
+	p.TeamKills++
+}
+
+// Frag counts a frag of the player on the named player with the weapon, fragging oneself costs a point.
+func (p *Player) Frag(name string, weapon string) {
 	if name == p.Name {
 		p.Scores[name]--
 	} else {
 		p.Scores[name]++
+		p.Weapons[weapon]++
 	}
 }
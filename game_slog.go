@@ -1,18 +1,39 @@
-package main
+package warsowlog
 
 import (
 	"log/slog"
+	"math"
 )
 
+// Slog returns the player as a group, it runs for every player of every record so it avoids slog.Group
+// and its conversions to any.
 func (p *Player) Slog(prefix string) slog.Attr {
-	return slog.Group(
-		prefix,
+	return slog.Attr{Key: prefix, Value: slog.GroupValue(
 		slog.String("name", p.Name),
 		slog.String("text_name", p.TextName),
 		slog.String("ip", p.IP),
 		slog.Bool("connected", p.connected),
 		slog.Bool("is_bot", p.IsBot()),
-	)
+		slog.String("team", p.Team),
+		slog.String("team_display", p.TeamDisplay),
+		slog.Bool("spectator", p.Spectator),
+		slog.Int("team_kills", p.TeamKills),
+		slog.Int("captures", p.Captures),
+		slog.Int64("best_time_ms", p.BestTime.Milliseconds()),
+		slog.Int("reconnects", p.Reconnects),
+		slog.Bool("ip_changed", p.ipChanged),
+		slog.Int("disconnects", p.Disconnects),
+		slog.Int("timeouts", p.Timeouts),
+		slog.Int("deaths", p.Deaths),
+		slog.Float64("kd", math.Round(p.KD()*100)/100),
+		slog.Int("net_score", p.NetScore()),
+		slog.Int("best_spree", p.BestSpree),
+		slog.Attr{Key: "multikills", Value: slog.GroupValue(
+			slog.Int("double", p.Multikills["double"]),
+			slog.Int("triple", p.Multikills["triple"]),
+			slog.Int("mega", p.Multikills["mega"]),
+		)},
+	)}
 }
 
 func (p *Player) SlogScores() []slog.Attr {
@@ -27,5 +48,18 @@ func (p *Player) SlogScores() []slog.Attr {
 		}
 	}
 	scores = append(scores, slog.Int("@@total@@", total))
+	if len(p.Weapons) > 0 {
+		scores = append(scores, slog.Any("@@weapons@@", p.Weapons))
+	}
 	return scores
 }
+
+func (r Result) SlogAttrs() []slog.Attr {
+	return []slog.Attr{
+		slog.String("outcome", string(r.Outcome)),
+		slog.String("winner", r.Winner),
+		slog.String("loser", r.Loser),
+		slog.String("final_score", r.FinalScore),
+		slog.Int("margin", r.Margin),
+	}
+}
@@ -0,0 +1,127 @@
+package warsowlog
+
+import (
+	"testing"
+	"time"
+)
+
+// disconnect disconnects the player as if it happened ago.
+func disconnect(p *Player, ago time.Duration, now time.Time) {
+	p.Disconnect("")
+	p.disconnectedAt = now.Add(-ago)
+}
+
+func TestEvictAfter(t *testing.T) {
+	now := time.Now()
+	g := NewGame("dm").WithLimits(10*time.Minute, 0)
+	disconnect(g.AddPlayer("old", "1.2.3.4"), 11*time.Minute, now)
+	disconnect(g.AddPlayer("recent", "1.2.3.5"), 9*time.Minute, now)
+	g.AddPlayer("connected", "1.2.3.6")
+
+	g.Evict(now)
+
+	if g.HasPlayer("old") {
+		t.Error("old was disconnected for longer than evictAfter and should be evicted")
+	}
+	for _, name := range []string{"recent", "connected"} {
+		if !g.HasPlayer(name) {
+			t.Errorf("%s should be kept", name)
+		}
+	}
+}
+
+func TestEvictMaxPlayers(t *testing.T) {
+	now := time.Now()
+	g := NewGame("dm").WithLimits(0, 3)
+	disconnect(g.AddPlayer("first", "1.2.3.4"), 3*time.Minute, now)
+	disconnect(g.AddPlayer("second", "1.2.3.5"), 2*time.Minute, now)
+	disconnect(g.AddPlayer("third", "1.2.3.6"), time.Minute, now)
+
+	// a new player makes room by evicting the oldest disconnected one
+	g.AddPlayer("fourth", "1.2.3.7")
+
+	if g.HasPlayer("first") {
+		t.Error("first was disconnected the longest and should be evicted")
+	}
+	for _, name := range []string{"second", "third", "fourth"} {
+		if !g.HasPlayer(name) {
+			t.Errorf("%s should be kept", name)
+		}
+	}
+	if n := len(g.Players()); n != 3 {
+		t.Errorf("expected 3 players, got %d", n)
+	}
+}
+
+func TestEvictKeepsScoredPlayers(t *testing.T) {
+	now := time.Now()
+	g := NewGame("dm").WithLimits(time.Minute, 1)
+	scorer := g.AddPlayer("scorer", "1.2.3.4")
+	victim := g.AddPlayer("victim", "1.2.3.5")
+	scorer.Frag(victim.Name, "rocket")
+	disconnect(scorer, time.Hour, now)
+	disconnect(victim, time.Hour, now)
+
+	g.Evict(now)
+
+	if !g.HasPlayer("scorer") {
+		t.Error("scorer scored and should never be evicted, even above maxPlayers")
+	}
+	if g.HasPlayer("victim") {
+		t.Error("victim never scored and should be evicted")
+	}
+}
+
+func TestEvictKeepsPlayersWithoutFrags(t *testing.T) {
+	now := time.Now()
+	g := NewGame("dm").WithLimits(time.Minute, 1)
+	g.AddPlayer("racer", "1.2.3.4").RaceTime(42 * time.Second)
+	g.AddPlayer("capturer", "1.2.3.5").Captures++
+	g.AddPlayer("victim", "1.2.3.6").Deaths++
+	g.AddPlayer("teamkiller", "1.2.3.7").TeamKills++
+	g.AddPlayer("idle", "1.2.3.8")
+	for _, p := range g.Players() {
+		disconnect(p, time.Hour, now)
+	}
+
+	g.Evict(now)
+
+	for _, name := range []string{"racer", "capturer", "victim", "teamkiller"} {
+		if !g.HasPlayer(name) {
+			t.Errorf("%s played and should never be evicted", name)
+		}
+	}
+	if g.HasPlayer("idle") {
+		t.Error("idle never played and should be evicted")
+	}
+}
+
+func TestNewGameIDsAreUnique(t *testing.T) {
+	seen := make(map[string]bool)
+	for range 1000 {
+		id := NewGame("dm").ID
+		if seen[id] {
+			t.Fatalf("game id %s was given twice", id)
+		}
+		seen[id] = true
+	}
+}
+
+func TestRoundsWithoutStartLines(t *testing.T) {
+	g := NewGame("bomb")
+	g.RecordRoundFrag("Sid")
+	g.EndRound("ALPHA")
+	// some gametypes print several end of round lines
+	g.EndRound("")
+	if n := g.Rounds(); n != 1 {
+		t.Fatalf("expected 1 round after repeated end lines, got %d", n)
+	}
+	g.RecordRoundFrag("Sid")
+	g.EndRound("BETA")
+	// a bomb plant then its explosion, without any frag
+	g.StartRound(0)
+	g.EndRound("")
+	if n := g.Rounds(); n != 3 {
+		t.Errorf("expected 3 rounds, got %d", n)
+	}
+}
@@ -0,0 +1,101 @@
+package warsowlog
+
+import (
+	"math"
+	"time"
+)
+
+// Glicko-2 as described by Glickman in "Example of the Glicko-2 system",
+// each game is a rating period made of the duels between its players.
+const (
+	// glickoDeviation is the deviation of a player never seen, the most uncertain rating
+	glickoDeviation = 350
+	// glickoVolatility is the volatility of a player never seen
+	glickoVolatility = 0.06
+	// glickoTau constrains the change of the volatility over time
+	glickoTau = 0.5
+	// glickoScale converts ratings to the Glicko-2 scale
+	glickoScale = 173.7178
+	// glickoIdlePeriod is how long a player must stay away for their deviation to grow of one period,
+	// so the rating of a player coming back after weeks moves faster
+	glickoIdlePeriod = 24 * time.Hour
+)
+
+// glicko2 returns the new ratings of the players, from the ratings before the game.
+func (r *Ratings) glicko2(scores map[string]int, sides map[string]int, now time.Time) map[string]Rating {
+	updated := make(map[string]Rating, len(scores))
+	for name, score := range scores {
+		rating := *r.Players[name]
+		mu := (rating.Rating - DefaultRating) / glickoScale
+		phi := idleDeviation(rating, now) / glickoScale
+		v, improvement := 0.0, 0.0
+		for opponent, opponentScore := range scores {
+			if sides[opponent] == sides[name] {
+				continue
+			}
+			o := r.Players[opponent]
+			g := glickoG(idleDeviation(*o, now) / glickoScale)
+			e := 1 / (1 + math.Exp(-g*(mu-(o.Rating-DefaultRating)/glickoScale)))
+			v += g * g * e * (1 - e)
+			improvement += g * (duel(score, opponentScore) - e)
+		}
+		v = 1 / v
+		sigma := glickoSigma(phi, rating.Volatility, v, v*improvement)
+		phi = 1 / math.Sqrt(1/(phi*phi+sigma*sigma)+1/v)
+		mu += phi * phi * improvement
+		rating.Rating = mu*glickoScale + DefaultRating
+		rating.Deviation = phi * glickoScale
+		rating.Volatility = sigma
+		updated[name] = rating
+	}
+	return updated
+}
+
+// idleDeviation returns the deviation of the player grown by the periods they did not play.
+func idleDeviation(rating Rating, now time.Time) float64 {
+	periods := math.Floor(now.Sub(rating.LastGame).Hours() / glickoIdlePeriod.Hours())
+	if periods <= 0 {
+		return rating.Deviation
+	}
+	phi := rating.Deviation / glickoScale
+	phi = math.Sqrt(phi*phi + periods*rating.Volatility*rating.Volatility)
+	return math.Min(phi*glickoScale, glickoDeviation)
+}
+
+func glickoG(phi float64) float64 {
+	return 1 / math.Sqrt(1+3*phi*phi/(math.Pi*math.Pi))
+}
+
+// glickoSigma returns the new volatility of a player, found with the Illinois algorithm (step 5).
+func glickoSigma(phi, sigma, v, delta float64) float64 {
+	const epsilon = 0.000001
+	a := math.Log(sigma * sigma)
+	f := func(x float64) float64 {
+		ex := math.Exp(x)
+		d := phi*phi + v + ex
+		return ex*(delta*delta-d)/(2*d*d) - (x-a)/(glickoTau*glickoTau)
+	}
+	A := a
+	var B float64
+	if delta*delta > phi*phi+v {
+		B = math.Log(delta*delta - phi*phi - v)
+	} else {
+		k := 1.0
+		for f(a-k*glickoTau) < 0 {
+			k++
+		}
+		B = a - k*glickoTau
+	}
+	fA, fB := f(A), f(B)
+	for math.Abs(B-A) > epsilon {
+		C := A + (A-B)*fA/(fB-fA)
+		fC := f(C)
+		if fC*fB <= 0 {
+			A, fA = B, fB
+		} else {
+			fA /= 2
+		}
+		B, fB = C, fC
+	}
+	return math.Exp(A / 2)
+}
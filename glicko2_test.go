@@ -0,0 +1,51 @@
+package warsowlog
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// The example of Glickman's "Example of the Glicko-2 system": a player rated 1500 with a deviation of 200
+// beats a player rated 1400 and loses to players rated 1550 and 1700.
+func TestGlicko2Example(t *testing.T) {
+	now := time.Now()
+	r := &Ratings{System: RatingGlicko2, Players: map[string]*Rating{
+		"player": {Rating: 1500, Deviation: 200, Volatility: 0.06, LastGame: now},
+		"1400":   {Rating: 1400, Deviation: 30, Volatility: 0.06, LastGame: now},
+		"1550":   {Rating: 1550, Deviation: 100, Volatility: 0.06, LastGame: now},
+		"1700":   {Rating: 1700, Deviation: 300, Volatility: 0.06, LastGame: now},
+	}}
+	scores := map[string]int{"1550": 0, "1700": 0, "player": -1, "1400": -2}
+	sides := map[string]int{"player": 0, "1400": 1, "1550": 2, "1700": 3}
+
+	got := r.glicko2(scores, sides, now)["player"]
+
+	want := Rating{Rating: 1464.06, Deviation: 151.52, Volatility: 0.05999}
+	if math.Abs(got.Rating-want.Rating) > 0.01 || math.Abs(got.Deviation-want.Deviation) > 0.01 ||
+		math.Abs(got.Volatility-want.Volatility) > 0.00001 {
+		t.Errorf("got %.2f / %.2f / %.5f, want %.2f / %.2f / %.5f",
+			got.Rating, got.Deviation, got.Volatility, want.Rating, want.Deviation, want.Volatility)
+	}
+}
+
+func TestGlickoSigma(t *testing.T) {
+	// the intermediate values of the example: phi, v and delta on the Glicko-2 scale
+	if got := glickoSigma(1.1513, 0.06, 1.7785, -0.4834); math.Abs(got-0.05999) > 0.00001 {
+		t.Errorf("got %.5f, want 0.05999", got)
+	}
+}
+
+func TestIdleDeviation(t *testing.T) {
+	now := time.Now()
+	rating := Rating{Rating: 1500, Deviation: 50, Volatility: 0.06, LastGame: now.Add(-3 * glickoIdlePeriod)}
+	phi := 50 / glickoScale
+	want := math.Sqrt(phi*phi+3*0.06*0.06) * glickoScale
+	if got := idleDeviation(rating, now); math.Abs(got-want) > 1e-9 {
+		t.Errorf("got %v, want %v", got, want)
+	}
+	rating.LastGame = now
+	if got := idleDeviation(rating, now); got != 50 {
+		t.Errorf("a player who just played keeps their deviation, got %v", got)
+	}
+}
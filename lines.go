@@ -0,0 +1,363 @@
+package warsowlog
+
+import (
+	"log/slog"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fabienjuif/warsowlog/color"
+)
+
+// matchDelimiter is printed by the server when a match ends, around the scoreboard.
+const matchDelimiter = "-------------------------------------"
+
+var (
+	reNewGame    = regexp.MustCompile(`^Gametype\s+'([^']+)'\s+initialized`)
+	reConnection = regexp.MustCompile(`^(.+)\sconnected\sfrom\s([\d\.]+):\d+`)
+	reEnter      = regexp.MustCompile(`^(.+)\sentered the game`)
+	reJoinTeam   = regexp.MustCompile(`^(.+)\sjoined the ([^\s]+) team.`)
+	reSpeak      = regexp.MustCompile(`^(.+):\s(.+)`)
+	// - Spectator (example: "Sid^7 joined the spectators" or "Sid^7 is now spectating")
+	reSpectate = regexp.MustCompile(`^(.+?)\s(?:\^7)?(?:joined the spectators|is now spectating)`)
+	// - Rename (example: "Sid^7 is now known as Monada^7")
+	reRename = regexp.MustCompile(`^(.+?)\s(?:\^7)?is now known as\s(.+)$`)
+	// - with an optional reason (example: "Sid^7 disconnected (timed out)" or "Sid^7 disconnected: overflow")
+	reDisconnection = regexp.MustCompile(`^(.+?)\sdisconnected(?:\s*\(([^)]+)\)|:\s*(.+))?`)
+	// - Kick or ban, with an optional reason (example: "Sid^7 was kicked (teamkilling)" or "Sid^7 has been banned: cheating")
+	reModeration = regexp.MustCompile(`^(.+?)\s(?:\^7)?(?:was|has been)\s(kicked|banned)(?:\sfrom the server)?(?:\s*\(([^)]+)\)|:\s*(.+))?`)
+
+	// all these regexp are for frags
+	// - Instagib frag (example:  "%APPDATA%^7 was instagibbed by Sid^7's instabeam")
+	reFragInstagib = regexp.MustCompile(`^(.+)\swas instagibbed by (.+)'s instabeam`)
+	// - Rocket launcher frag (example: "P.E.#1^7 ate Monada^7's rocket")
+	reFragRocketLauncher = regexp.MustCompile(`^(.+)\sate (.+)'s rocket`)
+	// - P.E.#1^7 almost dodged Monada^7's rocket
+	reFragRockerLauncher2 = regexp.MustCompile(`^(.+)\salmost dodged (.+)'s rocket`)
+	// - Riotgun frag (example: "P.E.#1^7 was shred by Monada^7's riotgun")
+	reFragRiotgun = regexp.MustCompile(`^(.+)\swas shred by (.+)'s riotgun`)
+	// - Lasergun frag (example: "P.E.#1^7 was cut by Monada^7's lasergun")
+	reLasergun = regexp.MustCompile(`^(.+)\swas cut by (.+)'s lasergun`)
+	// - Plasmagun frag (example: "P.E.#1^7 was melted by Monada^7's plasmagun")
+	rePlasmaGun = regexp.MustCompile(`^(.+)\swas melted by (.+)'s plasmagun`)
+	// - GrenadeLauncher frag (example: "P.E.#1^7 didn't see Monada^7's grenade")
+	reGrenadeLauncher = regexp.MustCompile(`^(.+)\sdidn't see (.+)'s grenade`)
+	// - Grenade Launcher frag (example: "P.E.#1^7 was popped by Monada^7's grenade")
+	reGrenadeLauncher2 = regexp.MustCompile(`^(.+)\swas popped by (.+)'s grenade`)
+	// - Gunblade frag (example: "P.E.#1^7 was impaled by Monada^7's gunblade")
+	reFragGunblade = regexp.MustCompile(`^(.+)\swas impaled by (.+)'s gunblade`)
+	// - Gunblade blast frag (example: "P.E.#1^7 could not hide from Monada^7's gunblade blast")
+	reFragGunbladeBlast = regexp.MustCompile(`^(.+)\scould not hide from (.+)'s gunblade`)
+	// - Electrobolt frag (example: "P.E.#1^7 was bolted by Monada^7's electrobolt")
+	reFragElectrobolt = regexp.MustCompile(`^(.+)\swas bolted by (.+)'s electrobolt`)
+	// - Electrobolt frag (example: "P.E.#1^7 was electrocuted by Monada^7's electrobolt")
+	reFragElectrobolt2 = regexp.MustCompile(`^(.+)\swas electrocuted by (.+)'s electrobolt`)
+	// - Machinegun frag (example: "P.E.#1^7 was gunned down by Monada^7's machinegun")
+	reFragMachinegun = regexp.MustCompile(`^(.+)\swas gunned down by (.+)'s machinegun`)
+	// - Splash damage frag of any splash weapon (example: "P.E.#1^7 was too close to Monada^7's grenade")
+	reFragSplash = regexp.MustCompile(`^(.+)\s(?:almost dodged|was too close to) (.+)'s (rocket|grenade|plasma|shockwave)`)
+	// - Telefrag (example: "P.E.#1^7 tried to invade Monada^7's personal space")
+	reFragTelefrag = regexp.MustCompile(`^(.+)\stried to invade (.+)'s personal space`)
+	// - Environmental death, there is no killer (example: "P.E.#1^7 cratered" or "P.E.#1^7 sank like a rock")
+	reWorldDeath = regexp.MustCompile(`^(.+?)\s(?:\^7)?(?:was melted|fell to his death|cratered|was squished|sank like a rock)\.?$`)
+	// - Self frag (example: "P.E.#1 ^7died"
+	reSelfFrag = regexp.MustCompile(`^(.+)\s\^7died`)
+
+	// - CTF flag action (example: "P.E.#1^7 got the ^1RED^7 flag!" or "P.E.#1^7 captured the flag!")
+	reCTF = regexp.MustCompile(`^(.+?)\s(?:\^7)?(got|took|captured|returned|dropped|lost) the (?:(\S+) )?flag`)
+
+	// - Bomb planted or defused (example: "Bomb planted by P.E.#1^7!" or "P.E.#1^7 defused the bomb")
+	reBombBy     = regexp.MustCompile(`(?i)^bomb (?:has been )?(planted|defused) by (.+?)!?$`)
+	reBombPlayer = regexp.MustCompile(`^(.+?)\s(?:\^7)?(planted|defused) the bomb`)
+	// - Bomb exploded (example: "The bomb exploded!")
+	reBombExploded = regexp.MustCompile(`(?i)^(?:the )?bomb (?:has )?exploded`)
+
+	// - Gametype vote passed (example: "Vote gametype ca passed")
+	reGametypeVote = regexp.MustCompile(`(?i)^(?:call)?vote\s+gametype\s+['"]?(\w+)['"]?.*\bpassed`)
+	// - Vote called (example: "Sid^7 requested to map wca1" or "Sid^7 called a vote: kick Monada")
+	reVoteCalled = regexp.MustCompile(`^(.+?)\s(?:\^7)?(?:requested to|called a vote:?)\s+(\w+)\s*(.*?)\s*$`)
+	// - Vote result (example: "Vote map wca1 passed" or "Callvote kick Monada failed")
+	reVoteResult = regexp.MustCompile(`(?i)^(?:call)?vote\s+(\w+)\s*(.*?)\s+(passed|failed|canceled|cancelled)`)
+	// - Match pause, with the player calling it if any (example: "Sid^7 called a timeout" or "Match paused")
+	rePause = regexp.MustCompile(`(?i)^(?:(.+?)\s(?:\^7)?called a timeout|timeout called by (.+?)!?$|match paused)`)
+	// - Match resume (example: "Sid^7 called a timein" or "Match resumed")
+	reResume = regexp.MustCompile(`(?i)^(?:(.+?)\s(?:\^7)?called a timein|timein called by (.+?)!?$|match resumed)`)
+	// - Overtime (example: "Overtime!" or "Sudden death!")
+	reOvertime = regexp.MustCompile(`(?i)^(?:match extended:?\s*)?(?:overtime|sudden death)\b`)
+	// - Warmup (example: "Warmup started" or "Warmup")
+	reWarmup = regexp.MustCompile(`(?i)^warm-?up\b`)
+	// - Countdown before the match goes live (example: "Match starts in 10 seconds" or "Countdown started")
+	reCountdown = regexp.MustCompile(`(?i)^(?:match (?:starts|starting|will start) in\b|countdown\b)`)
+	// - Map restart (example: "map_restart" or "Restarting map...")
+	reMapRestart = regexp.MustCompile(`(?i)^(?:map_restart\b|restarting (?:the )?map)`)
+
+	// - Race time (example: "P.E.#1^7 finished the race in 1:23.456" or "P.E.#1^7 finished in 53.120")
+	reRaceTime = regexp.MustCompile(`^(.+?)\s(?:\^7)?finished(?: the race)? in (?:(\d+):)?(\d+)\.(\d{1,3})`)
+
+	// - Round start (example: "Round 3 has started!" or "Round started")
+	reRoundStart = regexp.MustCompile(`(?i)^round(?:\s+(\d+))?\s+(?:has\s+)?(?:started|begins)`)
+	// - Round end (example: "ALPHA wins the round!" or "Round ended")
+	reRoundEnd = regexp.MustCompile(`(?i)^(?:(.+?)\s(?:\^7)?wins the round|round(?:\s+\d+)?\s+(?:has\s+)?(?:ended|is over))`)
+
+	// - Map loading (example: "SpawnServer: wdm4" or "Loading map: wdm4")
+	reMap = regexp.MustCompile(`^(?:SpawnServer:|Loading map:?)\s*(\S+)`)
+
+	// all these regexp are for download and pure server errors, they explain why players can not join
+	// - Missing pk3 (example: "Couldn't find pak file: wdm4.pk3")
+	reMissingFile = regexp.MustCompile(`(?i)(?:couldn't|could not|can't|cannot|failed to)\s(?:find|open|load)\b`)
+	// - Pure mismatch (example: "Pure check failed for client Sid: map_wdm4.pk3")
+	rePureMismatch = regexp.MustCompile(`(?i)\bpure\b.*\b(?:mismatch|fail(?:ed|ure)?|not match)`)
+	// - Download failure (example: "Download of map_wdm4.pk3 failed: refused by server")
+	reDownloadError = regexp.MustCompile(`(?i)\bdownload.*\b(?:fail(?:ed|ure)?|error|refused|denied|not allowed)`)
+	// - File involved in one of the lines above
+	rePk3File = regexp.MustCompile(`[\w\-./]+\.pk3\b`)
+
+	// - Server warning (example: "WARNING: Couldn't load sound")
+	reServerWarning = regexp.MustCompile(`^WARNING:`)
+	// - Server error (example: "ERROR: Server is full" or "Com_Error: ...")
+	reServerError = regexp.MustCompile(`^ERROR:|Com_Error`)
+
+	// - End of match scoreboard header (example: "Name            Score Ping Time")
+	reScoreboardHeader = regexp.MustCompile(`^\s*Name\s+Score\s+Ping\s+Time\s*$`)
+	// - End of match scoreboard row, only looked for after the end of match delimiter (example: "Sid^7           25   48 12:03")
+	reScoreboardRow = regexp.MustCompile(`^\s*(\S.*?)\s+(-?\d+)\s+(\d+)\s+(\d+):(\d{2})\s*$`)
+
+	// - Engine banner printed on startup (example: "Warsow 2.1.2 x86_64 Mar 22 2017")
+	reVersion = regexp.MustCompile(`^(Warsow|Warfork|qfusion)\s+v?(\d+(?:\.\d+)+)`)
+
+	// supportedVersions are the engine version prefixes whose messages are fully covered by the parser
+	supportedVersions = []string{"Warsow 2.1"}
+
+	// splashWeapons maps the weapon name of splash damage messages to the weapon of the frag
+	splashWeapons = map[string]string{
+		"rocket":    "rocket",
+		"grenade":   "grenade",
+		"plasma":    "plasmagun",
+		"shockwave": "shockwave",
+	}
+
+	// ctfActions maps the verb of a CTF message to the flag action
+	ctfActions = map[string]string{
+		"got":      "taken",
+		"took":     "taken",
+		"captured": "captured",
+		"returned": "returned",
+		"dropped":  "dropped",
+		"lost":     "dropped",
+	}
+
+	// moderationKinds maps the verb of a kick or ban message to the kind of the record
+	moderationKinds = map[string]string{
+		"kicked": "kick",
+		"banned": "ban",
+	}
+
+	// since we try to parse what people say and this is very close to system message we have to create a blacklist
+	// of player names (so we detect them as system messages)
+	// sadly anybody with this name will not be detected as a player when they speak
+	playerNameBlacklist = map[string]bool{
+		"G_LoadGameScript":        true,
+		"       ":                 true,
+		"Opening UDP/IP socket":   true,
+		"Opening UDP/IPv6 socket": true,
+		"SpawnServer":             true,
+	}
+)
+
+// isSupportedVersion returns true if the version is one of supportedVersions or one of its patches.
+func isSupportedVersion(version string) bool {
+	for _, v := range supportedVersions {
+		if version == v || strings.HasPrefix(version, v+".") {
+			return true
+		}
+	}
+	return false
+}
+
+func playerFlat(name string) string {
+	return color.Strip(name)
+}
+
+// parseFrag returns the victim, the killer and the weapon of a frag line, the weapon is empty if the line is not a frag.
+// The killer is empty for environmental deaths (weapon "world").
+func parseFrag(text string) (string, string, string) {
+	// %APPDATA%^7 was instagibbed by Sid^7's instabeam
+	if match := reFragInstagib.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "instagib"
+	}
+	// P.E.#1^7 ate Monada^7's rocket
+	if match := reFragRocketLauncher.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "rocket"
+	}
+	// P.E.#1^7 almost dodged Monada^7's rocket
+	if match := reFragRockerLauncher2.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "rocket"
+	}
+	// P.E.#1^7 was shred by Monada^7's riotgun
+	if match := reFragRiotgun.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "riotgun"
+	}
+	// P.E.#1^7 was cut by Monada^7's lasergun
+	if match := reLasergun.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "lasergun"
+	}
+	// P.E.#1^7 was melted by Monada^7's plasmagun
+	if match := rePlasmaGun.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "plasmagun"
+	}
+	// P.E.#1^7 didn't see Monada^7's grenade
+	if match := reGrenadeLauncher.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "grenade"
+	}
+	// P.E.#1^7 was popped by Monada^7's grenade
+	if match := reGrenadeLauncher2.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "grenade"
+	}
+	// P.E.#1^7 was impaled by Monada^7's gunblade
+	if match := reFragGunblade.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "gunblade"
+	}
+	// P.E.#1^7 could not hide from Monada^7's gunblade blast
+	if match := reFragGunbladeBlast.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "gunblade"
+	}
+	// P.E.#1^7 was bolted by Monada^7's electrobolt
+	if match := reFragElectrobolt.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "electrobolt"
+	}
+	// P.E.#1^7 was electrocuted by Monada^7's electrobolt
+	if match := reFragElectrobolt2.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "electrobolt"
+	}
+	// P.E.#1^7 was gunned down by Monada^7's machinegun
+	if match := reFragMachinegun.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "machinegun"
+	}
+	// P.E.#1^7 was too close to Monada^7's grenade
+	if match := reFragSplash.FindStringSubmatch(text); len(match) >= 4 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, splashWeapons[match[3]]
+	}
+	// P.E.#1^7 tried to invade Monada^7's personal space
+	if match := reFragTelefrag.FindStringSubmatch(text); len(match) >= 3 {
+		victim := match[1]
+		killer := match[2]
+		return victim, killer, "telefrag"
+	}
+	// P.E.#1^7 cratered
+	if match := reWorldDeath.FindStringSubmatch(text); len(match) >= 2 {
+		victim := match[1]
+		return victim, "", "world"
+	}
+	// P.E.#1^7 ^7died
+	if match := reSelfFrag.FindStringSubmatch(text); len(match) >= 2 {
+		victim := match[1]
+		killer := match[1]
+		return victim, killer, "self"
+	}
+
+	return "", "", ""
+}
+
+// parseFileError returns the kind of download or pure server error of the line, if any.
+// Only lines mentioning a pk3 are considered so players talking about downloads are not flagged.
+func parseFileError(text string) string {
+	if !rePk3File.MatchString(text) {
+		return ""
+	}
+	if rePureMismatch.MatchString(text) {
+		return "pure_mismatch"
+	}
+	if reDownloadError.MatchString(text) {
+		return "download_error"
+	}
+	if reMissingFile.MatchString(text) {
+		return "missing_file"
+	}
+	return ""
+}
+
+// parseServerLog returns the kind and level of a server warning or error line, if any.
+func parseServerLog(text string) (string, slog.Level) {
+	if reServerError.MatchString(text) {
+		return "server_error", slog.LevelError
+	}
+	if reServerWarning.MatchString(text) {
+		return "server_warning", slog.LevelWarn
+	}
+	return "", slog.LevelInfo
+}
+
+// parseBomb returns the action (planted, defused or exploded) and the player of a bomb line.
+// The player is empty for explosions.
+func parseBomb(text string) (string, string) {
+	if match := reBombBy.FindStringSubmatch(text); len(match) > 0 {
+		return strings.ToLower(match[1]), match[2]
+	}
+	if match := reBombPlayer.FindStringSubmatch(text); len(match) > 0 {
+		return match[2], match[1]
+	}
+	if reBombExploded.MatchString(text) {
+		return "exploded", ""
+	}
+	return "", ""
+}
+
+// parseRaceTime returns the player and the time of a race finish line, the player is empty if the line is not one.
+func parseRaceTime(text string) (string, time.Duration) {
+	match := reRaceTime.FindStringSubmatch(text)
+	if len(match) == 0 {
+		return "", 0
+	}
+	minutes, _ := strconv.Atoi(match[2])
+	seconds, _ := strconv.Atoi(match[3])
+	// 1:23.4 is 400 milliseconds, not 4
+	millis, _ := strconv.Atoi((match[4] + "00")[:3])
+	return match[1], time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second + time.Duration(millis)*time.Millisecond
+}
+
+// parseScoreboardRow returns the row of an end of match scoreboard line.
+func parseScoreboardRow(text string) (ScoreboardRow, bool) {
+	match := reScoreboardRow.FindStringSubmatch(text)
+	if len(match) == 0 {
+		return ScoreboardRow{}, false
+	}
+	score, _ := strconv.Atoi(match[2])
+	ping, _ := strconv.Atoi(match[3])
+	minutes, _ := strconv.Atoi(match[4])
+	seconds, _ := strconv.Atoi(match[5])
+	return ScoreboardRow{
+		Name:  match[1],
+		Score: score,
+		Ping:  ping,
+		Time:  time.Duration(minutes)*time.Minute + time.Duration(seconds)*time.Second,
+	}, true
+}
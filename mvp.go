@@ -0,0 +1,81 @@
+package warsowlog
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// MVPCriteria maps a gametype to the strategy electing its MVP.
+// Gametypes without criterion elect the best player of their summary ranking, see ScoringFor.
+// A nil strategy disables the MVP.
+type MVPCriteria map[string]ScoringStrategy
+
+// mvpStrategies are the strategies usable as MVP criterion, by name.
+var mvpStrategies = map[string]ScoringStrategy{
+	"frags":    fragCountScoring{},
+	"captures": captureScoring{},
+	"time":     raceScoring{},
+	"rounds":   roundScoring{},
+	"none":     nil,
+}
+
+// ParseMVPCriterion parses a "gametype=criterion" definition,
+// the criterion is frags, captures, time, rounds or none.
+func ParseMVPCriterion(definition string) (string, ScoringStrategy, error) {
+	gameType, name, ok := strings.Cut(definition, "=")
+	if !ok || gameType == "" {
+		return "", nil, fmt.Errorf("mvp criterion %q must look like gametype=criterion", definition)
+	}
+	strategy, ok := mvpStrategies[name]
+	if !ok {
+		return "", nil, fmt.Errorf("unknown mvp criterion %q (expected frags, captures, time, rounds or none)", name)
+	}
+	return gameType, strategy, nil
+}
+
+// For returns the strategy electing the MVP of the gametype, nil if it has no MVP.
+func (c MVPCriteria) For(gameType string) ScoringStrategy {
+	if s, ok := c[gameType]; ok {
+		return s
+	}
+	if _, ok := ScoringFor(gameType).(fragScoring); ok {
+		return fragCountScoring{}
+	}
+	return ScoringFor(gameType)
+}
+
+// fragCountScoring elects the player with the most frags of other players, suicides and team kills
+// do not take frags away like in the frag ranking, the deaths of the player break the ties.
+type fragCountScoring struct{}
+
+func (fragCountScoring) Name() string { return "frags" }
+
+func (fragCountScoring) Score(_ *Game, p *Player) int { return p.Frags() }
+
+// MVP returns the best player according to the strategy, ties are broken by the fewest deaths,
+// then like in Ranking. It returns false if no player can be elected.
+func MVP(g *Game, s ScoringStrategy, players []*Player) (*Player, bool) {
+	candidates := make([]*Player, 0, len(players))
+	for _, p := range players {
+		if s.Score(g, p) != NoScore {
+			candidates = append(candidates, p)
+		}
+	}
+	if len(candidates) == 0 {
+		return nil, false
+	}
+	sort.Slice(candidates, func(i, j int) bool {
+		if si, sj := s.Score(g, candidates[i]), s.Score(g, candidates[j]); si != sj {
+			return si > sj
+		}
+		if di, dj := candidates[i].Deaths, candidates[j].Deaths; di != dj {
+			return di < dj
+		}
+		if ti, tj := candidates[i].Total(), candidates[j].Total(); ti != tj {
+			return ti > tj
+		}
+		return candidates[i].Name < candidates[j].Name
+	})
+	return candidates[0], true
+}
@@ -0,0 +1,51 @@
+package warsowlog
+
+import "testing"
+
+func TestMVPCriteriaFor(t *testing.T) {
+	criteria := MVPCriteria{"ctf": fragScoring{}, "duel": nil}
+	tests := []struct {
+		gameType string
+		want     string
+	}{
+		{"dm", "frags"},
+		{"race", "time"},
+		{"ca", "rounds"},
+		{"bomb", "rounds"},
+		{"ctf", "frags"},
+	}
+	for _, tt := range tests {
+		if got := criteria.For(tt.gameType).Name(); got != tt.want {
+			t.Errorf("MVP criterion of %s = %s, want %s", tt.gameType, got, tt.want)
+		}
+	}
+	if got := (MVPCriteria{}).For("ctf").Name(); got != "captures" {
+		t.Errorf("MVP criterion of ctf without configuration = %s, want captures", got)
+	}
+	if got := criteria.For("duel"); got != nil {
+		t.Errorf("MVP of duel should be disabled, got %s", got.Name())
+	}
+}
+
+func TestMVPFragsThenDeaths(t *testing.T) {
+	g := NewGame("dm")
+	a, b, c := g.AddPlayer("A", "1.2.3.4"), g.AddPlayer("B", "1.2.3.5"), g.AddPlayer("C", "1.2.3.6")
+	for range 2 {
+		a.Frag("C", "rocket")
+		b.Frag("C", "rocket")
+		c.Deaths += 2
+	}
+	c.Frag("A", "rocket")
+	a.Deaths += 2
+	// a suicide lowers the total of B but not its frags
+	b.Frag("B", "rocket")
+	b.Deaths++
+
+	mvp, ok := MVP(g, MVPCriteria{}.For("dm"), g.Playing())
+	if !ok {
+		t.Fatal("expected an MVP")
+	}
+	if mvp.Name != "B" {
+		t.Errorf("A and B have 2 frags, B died less and should be MVP, got %s", mvp.Name)
+	}
+}
@@ -0,0 +1,614 @@
+package warsowlog
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"runtime/debug"
+	"strconv"
+	"strings"
+	"sync/atomic"
+	"time"
+
+	"github.com/fabienjuif/warsowlog/color"
+)
+
+// Options tunes the behaviour of the Parser.
+type Options struct {
+	// EvictAfter is how long a disconnected player without score is kept in the game
+	EvictAfter time.Duration
+	// MaxPlayers caps the number of players kept in the game (0 means no limit)
+	MaxPlayers int
+	// Bots controls how bots are handled in frags and summaries, everything is kept if empty
+	Bots BotPolicy
+	// MinPlayers is the number of human players under which a game is unranked
+	MinPlayers int
+	// MinDuration is the game duration under which a game is unranked
+	MinDuration time.Duration
+	// NetsplitWindow is the window in which timeouts are counted to detect a netsplit
+	NetsplitWindow time.Duration
+	// NetsplitRatio is the fraction of players timing out within the window that raises a netsplit alert (0 to disable)
+	NetsplitRatio float64
+	// Sanitizer cleans player names, sanitizePlayer is used if nil
+	Sanitizer NameSanitizer
+	// SummaryFields are user defined fields computed for each player in the end of game summary
+	SummaryFields []SummaryField
+	// NameChurn is the number of distinct names an IP can use in a game before it is flagged (0 to disable)
+	NameChurn int
+	// MultikillWindow is the time in which consecutive frags of a player make a multikill (0 to disable)
+	MultikillWindow time.Duration
+	// SpreeFrags is the number of frags without dying that makes a killing spree (0 to disable)
+	SpreeFrags int
+	// MVP elects the most valuable player of each gametype in the end of game summary
+	MVP MVPCriteria
+	// Ratings are updated with the standings of every ranked game, unrated if nil
+	Ratings *Ratings
+	// Handler receives the records, the default slog handler is used if nil
+	Handler slog.Handler
+}
+
+// BotPolicy controls how bots are handled end-to-end.
+type BotPolicy string
+
+const (
+	// BotsKeep keeps every frag and every player
+	BotsKeep BotPolicy = "keep"
+	// BotsDropFrags does not count frags between two bots
+	BotsDropFrags BotPolicy = "drop-frags"
+	// BotsExclude does not count frags between two bots and excludes bots from summaries
+	BotsExclude BotPolicy = "exclude"
+)
+
+// dropsFrags returns true if frags between two bots are not counted, the empty policy keeps them like BotsKeep.
+func (b BotPolicy) dropsFrags() bool {
+	return b == BotsDropFrags || b == BotsExclude
+}
+
+func ParseBotPolicy(s string) (BotPolicy, error) {
+	switch policy := BotPolicy(s); policy {
+	case BotsKeep, BotsDropFrags, BotsExclude:
+		return policy, nil
+	default:
+		return "", fmt.Errorf("unknown bot policy %q (expected %s, %s or %s)", s, BotsKeep, BotsDropFrags, BotsExclude)
+	}
+}
+
+// Parser turns console lines into log records and keeps track of the current game.
+type Parser struct {
+	opts   Options
+	logger *slog.Logger
+	// game stores the latest known game data
+	// when the command is ran after a game already started, the game is in a bad state
+	// the previous game is dropped when a new one starts, nothing else must hold it
+	game atomic.Pointer[Game]
+	// currentMap is the last map loaded, it outlives games since the map is loaded before the gametype
+	currentMap string
+	// pendingGameType is the gametype voted by players, it is applied on the next map restart
+	// since some setups do not print the "Gametype initialized" line when the vote is applied
+	pendingGameType string
+	// serverVersion is the engine version found in the startup banner, it is attached to every record
+	serverVersion string
+	// vote is the last vote called, its result is attributed to the caller
+	vote *vote
+	// scoreboard is the end of match block being read, the summary waits for its end
+	scoreboard *scoreboard
+	handlers   handlers
+	// queued are the typed events of the line being parsed, see dispatch
+	queued []any
+	// line is the line being parsed, for the errors
+	line string
+	// extra are the records of the line being parsed on top of its own, like multikills
+	extra []record
+	// decision is how the line being parsed was understood, nil if there is no OnDecision handler
+	decision *Decision
+}
+
+// vote is a callvote waiting for its result.
+type vote struct {
+	caller string
+	name   string
+	arg    string
+}
+
+// record is a log record waiting to be emitted.
+type record struct {
+	level slog.Level
+	msg   string
+	attrs []slog.Attr
+	// matchID is the match the record belongs to
+	matchID string
+}
+
+func NewParser(opts Options) *Parser {
+	p := &Parser{opts: opts, logger: slog.Default()}
+	if opts.Handler != nil {
+		p.logger = slog.New(opts.Handler)
+	}
+	game := p.newGame("")
+	// the logs may be bound to an already started game
+	game.match.State = MatchUnknown
+	p.game.Store(game)
+	return p
+}
+
+// sanitize cleans a player name with the configured sanitizer, using the game as roster.
+// Names that could designate several players are reported as ErrAmbiguousName.
+func (p *Parser) sanitize(game *Game, name string) string {
+	sanitized := sanitizePlayer(name)
+	if p.opts.Sanitizer != nil {
+		sanitized = p.opts.Sanitizer(name, game.HasPlayer)
+	}
+	p.decideName(name, sanitized)
+	if game.HasPlayer(sanitized) {
+		if raw := strings.TrimSpace(name); raw != sanitized && game.HasPlayer(raw) {
+			p.fail(fmt.Errorf("%w: %q is known as is and as %q", ErrAmbiguousName, raw, sanitized))
+		}
+		return sanitized
+	}
+	textName := playerFlat(sanitized)
+	// it runs for every new name, so it walks the roster instead of copying it
+	for _, player := range game.players {
+		if player.TextName == textName {
+			// players are told apart by their colors, but people read names without them
+			p.fail(fmt.Errorf("%w: %q has the same text as another player", ErrAmbiguousName, sanitized))
+			break
+		}
+	}
+	return sanitized
+}
+
+// normalizeRoundWinner returns the winner of a round as a known team or player name.
+// The server prints the team name with colors, players are looked up in the roster.
+func normalizeRoundWinner(game *Game, winner string) string {
+	if winner == "" || game.HasPlayer(winner) {
+		return winner
+	}
+	return NormalizeTeam(winner)
+}
+
+// appendTransition moves the current match to the state and appends a match_state record
+// if the state changed. It must be called within a command of the given game.
+func appendTransition(attrs []slog.Attr, game *Game, state MatchState) []slog.Attr {
+	previous := game.SetState(state)
+	if previous == state {
+		return attrs
+	}
+	return append(
+		attrs,
+		slog.String("kind", "match_state"),
+		slog.String("state", string(state)),
+		slog.String("previous_state", string(previous)),
+		slog.String("match_id", game.MatchID()),
+	)
+}
+
+// Snapshot returns a copy of the current game, it is safe to call from any goroutine.
+func (p *Parser) Snapshot() GameSnapshot {
+	return p.game.Load().Snapshot()
+}
+
+func (p *Parser) newGame(gameType string) *Game {
+	game := NewGame(gameType).WithLimits(p.opts.EvictAfter, p.opts.MaxPlayers)
+	game.Map = p.currentMap
+	return game
+}
+
+// Parse handles a single raw console line.
+// A panic while parsing the line is recovered and reported as a parser_panic record,
+// so one pathological line can never kill the whole process.
+func (p *Parser) Parse(ctx context.Context, line string) {
+	t := color.FromANSI(strings.TrimSuffix(line, color.ANSIReset))
+	p.line = t
+
+	defer func() {
+		if r := recover(); r != nil {
+			p.queued = nil
+			p.extra = nil
+			p.decision = nil
+			p.logger.LogAttrs(
+				ctx,
+				slog.LevelError,
+				"parser panic",
+				slog.String("kind", "parser_panic"),
+				slog.String("line", t),
+				slog.String("panic", fmt.Sprint(r)),
+				slog.String("stack", string(debug.Stack())),
+			)
+		}
+	}()
+
+	if len(p.handlers.decision) > 0 {
+		p.decision = &Decision{Line: t, Rule: "unmatched"}
+	}
+
+	var records []record
+	p.game.Load().Apply(func(game *Game) {
+		if p.scoreboard != nil {
+			var consumed bool
+			if records, consumed = p.readScoreboard(game, t); consumed {
+				p.decide("scoreboard")
+				return
+			}
+		}
+		level, attrs := p.parseLine(game, t)
+		if p.scoreboard == nil {
+			records = append(records, record{level: level, msg: t, attrs: attrs, matchID: p.game.Load().MatchID()})
+		}
+		// otherwise the line opened the scoreboard, the summary is its record
+		records = append(records, p.extra...)
+		p.extra = nil
+	})
+	if p.decision != nil {
+		p.decision.MatchID = p.game.Load().MatchID()
+		p.queue(*p.decision)
+		p.decision = nil
+	}
+	for _, r := range records {
+		p.emit(ctx, r)
+	}
+	p.dispatch(ctx)
+}
+
+// Flush emits the records still waiting for more lines, like the summary of a match whose scoreboard
+// is the end of the input. It must be called once the input is exhausted.
+func (p *Parser) Flush(ctx context.Context) {
+	var records []record
+	p.game.Load().Apply(func(game *Game) {
+		if p.scoreboard != nil {
+			records = append(records, p.closeScoreboard(game))
+		}
+	})
+	for _, r := range records {
+		p.emit(ctx, r)
+	}
+	p.dispatch(ctx)
+}
+
+func (p *Parser) emit(ctx context.Context, r record) {
+	if p.currentMap != "" {
+		r.attrs = append(r.attrs, slog.String("map", p.currentMap))
+	}
+	if p.serverVersion != "" {
+		r.attrs = append(r.attrs, slog.String("server_version", p.serverVersion))
+	}
+	p.logger.LogAttrs(ctx, r.level, r.msg, r.attrs...)
+	p.notify(ctx, r)
+}
+
+// parseLine must be called within a command of the given game.
+func (p *Parser) parseLine(game *Game, t string) (slog.Level, []slog.Attr) {
+	level := slog.LevelInfo
+	// most records have a few attributes, the summary grows it once
+	attrs := make([]slog.Attr, 0, 8)
+	if victim, killer, weapon := parseFrag(t); weapon != "" {
+		p.decide("frag", "victim", victim, "killer", killer, "weapon", weapon)
+		// this is a frag
+		// we need to sanitize the player name
+		victim = p.sanitize(game, victim)
+		weapon = strings.TrimSpace(weapon)
+
+		victimPlayer := game.AddPlayer(victim, "")
+		frag := FragEvent{MatchID: game.MatchID(), Phase: game.State(), Victim: victim, Weapon: weapon}
+		if killer == "" {
+			// environmental death, it costs a point like a suicide
+			victimPlayer.Frag(victim, weapon)
+			victimPlayer.Deaths++
+			p.trackStreaks(game, nil, victimPlayer)
+		} else {
+			killer = p.sanitize(game, killer)
+			frag.Killer = killer
+			killerPlayer := game.AddPlayer(killer, "")
+			if p.opts.Bots.dropsFrags() && killerPlayer.IsBot() && victimPlayer.IsBot() {
+				// bot vs bot frags are logged but not counted
+				frag.Dropped = true
+				attrs = append(attrs, slog.Bool("dropped", true))
+			} else if killer != victim && isTeam(killerPlayer.Team) && killerPlayer.Team == victimPlayer.Team {
+				killerPlayer.TeamKill(victim)
+				victimPlayer.Deaths++
+				// a team kill ends the spree of the victim but does not count for the killer
+				p.trackStreaks(game, nil, victimPlayer)
+				frag.TeamKill = true
+				attrs = append(attrs, slog.Bool("team_kill", true))
+			} else {
+				killerPlayer.Frag(victim, weapon)
+				victimPlayer.Deaths++
+				if killer != victim {
+					game.RecordRoundFrag(killer)
+					if game.RecordFirstBlood(killer) {
+						p.extra = append(p.extra, record{level: slog.LevelInfo, msg: t, matchID: game.MatchID(), attrs: []slog.Attr{
+							slog.String("kind", "first_blood"),
+							killerPlayer.Slog("player"),
+							victimPlayer.Slog("victim"),
+						}})
+					}
+					p.trackStreaks(game, killerPlayer, victimPlayer)
+				} else {
+					p.trackStreaks(game, nil, victimPlayer)
+				}
+			}
+			attrs = append(attrs, killerPlayer.Slog("killer"))
+		}
+
+		attrs = append(attrs, victimPlayer.Slog("victim"))
+		attrs = append(attrs, slog.String("weapon", weapon))
+		attrs = append(attrs, slog.String("phase", string(frag.Phase)))
+		if len(p.handlers.frag) > 0 {
+			p.queue(frag)
+		}
+	} else if strings.Contains(t, "All players are ready. Match starting!") {
+		p.decide("match_start")
+		if game.State() == MatchLive {
+			p.fail(fmt.Errorf("%w: match %s started twice", ErrStateConflict, game.MatchID()))
+		}
+		attrs = appendTransition(attrs, game, MatchLive)
+	} else if reWarmup.MatchString(t) {
+		p.decide("warmup")
+		attrs = appendTransition(attrs, game, MatchWarmup)
+	} else if reCountdown.MatchString(t) {
+		p.decide("countdown")
+		attrs = appendTransition(attrs, game, MatchCountdown)
+	} else if kind := parseFileError(t); kind != "" {
+		p.decide("file_error", "kind", kind)
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("kind", kind))
+		attrs = append(attrs, slog.String("file", rePk3File.FindString(t)))
+	} else if kind, serverLevel := parseServerLog(t); kind != "" {
+		p.decide("server_log", "kind", kind)
+		level = serverLevel
+		attrs = append(attrs, slog.String("kind", kind))
+	} else if match := reMap.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("map_load", "map", match[1])
+		p.currentMap = match[1]
+		game.Map = p.currentMap
+		attrs = append(attrs, slog.String("kind", "map_load"))
+	} else if match := reVersion.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("server_version", "engine", match[1], "version", match[2])
+		p.serverVersion = match[1] + " " + match[2]
+		if isSupportedVersion(p.serverVersion) {
+			attrs = append(attrs, slog.String("kind", "server_version"))
+		} else {
+			// the parser may miss or misread messages of this version
+			level = slog.LevelWarn
+			attrs = append(attrs, slog.String("kind", "compatibility_warning"))
+		}
+	} else if match := reEnter.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("enter", "player", match[1])
+		player := game.AddPlayer(p.sanitize(game, match[1]), "")
+		attrs = append(attrs, player.Slog("player"))
+	} else if match := reConnection.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("connection", "player", match[1], "ip", match[2])
+		player, reconnect := game.Connect(p.sanitize(game, match[1]), match[2])
+		if names := game.NamesOf(player.IP); p.opts.NameChurn > 0 && len(names) > p.opts.NameChurn {
+			// constant renaming is a common way to dodge mutes
+			level = slog.LevelWarn
+			attrs = append(
+				attrs,
+				slog.String("kind", "name_churn"),
+				slog.Any("names", names),
+			)
+		} else if reconnect {
+			attrs = append(attrs, slog.String("kind", "reconnect"))
+		}
+		attrs = append(attrs, player.Slog("player"))
+	} else if match := reRename.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("rename", "old_name", match[1], "new_name", match[2])
+		oldName := p.sanitize(game, match[1])
+		player := game.Rename(oldName, p.sanitize(game, match[2]))
+		attrs = append(attrs, slog.String("kind", "rename"))
+		attrs = append(attrs, slog.String("old_name", oldName))
+		attrs = append(attrs, player.Slog("player"))
+	} else if match := reSpectate.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("spectate", "player", match[1])
+		player := game.AddPlayer(p.sanitize(game, match[1]), "")
+		if !player.Spectator {
+			attrs = append(attrs, slog.String("kind", "spectator_join"))
+		}
+		player.Spectate()
+		attrs = append(attrs, player.Slog("player"))
+	} else if match := reJoinTeam.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("join_team", "player", match[1], "team", match[2])
+		player := game.AddPlayer(p.sanitize(game, match[1]), "")
+		wasSpectator := player.Spectator
+		previous := player.JoinTeam(match[2])
+		if player.Spectator != wasSpectator {
+			kind := "spectator_leave"
+			if player.Spectator {
+				kind = "spectator_join"
+			}
+			attrs = append(attrs, slog.String("kind", kind))
+		} else if previous != "" && previous != player.Team {
+			attrs = append(attrs, slog.String("kind", "team_change"))
+		}
+		if previous != "" && previous != player.Team {
+			attrs = append(attrs, slog.String("previous_team", previous))
+		}
+		attrs = append(attrs, player.Slog("player"))
+	} else if match := reDisconnection.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("disconnection", "player", match[1], "reason", match[2]+match[3])
+		reason := strings.ToLower(strings.TrimSpace(match[2] + match[3]))
+		name := p.sanitize(game, match[1])
+		if !game.HasPlayer(name) {
+			p.fail(fmt.Errorf("%w: unknown player %q disconnected", ErrStateConflict, name))
+		}
+		player := game.AddPlayer(name, "")
+		player.Disconnect(reason)
+		attrs = append(attrs, player.Slog("player"))
+		if reason != "" {
+			attrs = append(attrs, slog.String("reason", reason))
+		}
+		if isTimeout(reason) && p.opts.NetsplitRatio > 0 {
+			timeouts := game.RecordTimeout(time.Now(), p.opts.NetsplitWindow)
+			// timed out players are not connected anymore, they still were at the beginning of the window
+			ratio := float64(timeouts) / float64(timeouts+game.ConnectedCount())
+			if timeouts >= 2 && ratio >= p.opts.NetsplitRatio {
+				// a lot of players timing out at once is the classic symptom of host network trouble
+				level = slog.LevelError
+				attrs = append(
+					attrs,
+					slog.String("kind", "server_netsplit"),
+					slog.Int("timeouts", timeouts),
+					slog.Float64("timeout_ratio", ratio),
+				)
+				game.ResetTimeouts()
+			}
+		}
+	} else if match := reModeration.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("moderation", "player", match[1], "action", match[2], "reason", match[3]+match[4])
+		// a kicked or banned player leaves the server without a disconnection line
+		reason := strings.TrimSpace(match[3] + match[4])
+		player := game.AddPlayer(p.sanitize(game, match[1]), "")
+		player.Disconnect(match[2])
+		level = slog.LevelWarn
+		attrs = append(attrs, slog.String("kind", moderationKinds[match[2]]))
+		attrs = append(attrs, player.Slog("player"))
+		if reason != "" {
+			attrs = append(attrs, slog.String("reason", reason))
+		}
+	} else if match := reCTF.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("ctf", "player", match[1], "action", match[2], "flag", match[3])
+		action := ctfActions[match[2]]
+		player := game.AddPlayer(p.sanitize(game, match[1]), "")
+		if action == "captured" {
+			player.Captures++
+		}
+		attrs = append(attrs, slog.String("kind", "ctf"))
+		attrs = append(attrs, slog.String("action", action))
+		attrs = append(attrs, slog.String("flag", color.Strip(match[3])))
+		attrs = append(attrs, player.Slog("player"))
+	} else if name, raceTime := parseRaceTime(t); name != "" {
+		p.decide("race_time", "player", name, "time", raceTime.String())
+		player := game.AddPlayer(p.sanitize(game, name), "")
+		best := player.RaceTime(raceTime)
+		attrs = append(attrs, slog.String("kind", "race_time"))
+		attrs = append(attrs, player.Slog("player"))
+		attrs = append(attrs, slog.Int64("time_ms", raceTime.Milliseconds()))
+		attrs = append(attrs, slog.Bool("personal_best", best))
+	} else if match := reRoundStart.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("round_start", "round", match[1])
+		n, _ := strconv.Atoi(match[1])
+		game.StartRound(n)
+		attrs = append(attrs, slog.String("kind", "round_start"))
+		attrs = append(attrs, slog.Int("round", game.Round()))
+	} else if match := reRoundEnd.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("round_end", "winner", match[1])
+		winner := normalizeRoundWinner(game, p.sanitize(game, match[1]))
+		round := game.EndRound(winner)
+		attrs = append(attrs, slog.String("kind", "round_end"))
+		attrs = append(attrs, slog.Int("round", round))
+		if winner != "" {
+			attrs = append(attrs, slog.String("winner", winner))
+		}
+		attrs = append(attrs, slog.Any("frags", game.RoundFrags(round)))
+	} else if action, name := parseBomb(t); action != "" {
+		p.decide("bomb", "action", action, "player", name)
+		attrs = append(attrs, slog.String("kind", "bomb"))
+		attrs = append(attrs, slog.String("action", action))
+		attrs = append(attrs, slog.Int("round", game.Round()))
+		if name != "" {
+			player := game.AddPlayer(p.sanitize(game, name), "")
+			attrs = append(attrs, player.Slog("player"))
+		}
+		if action == "planted" {
+			// the round is under way even if its start was not printed
+			game.StartRound(0)
+		} else {
+			// a defused or exploded bomb ends the round
+			game.EndRound("")
+		}
+	} else if match := reGametypeVote.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("gametype_vote", "game_type", match[1])
+		p.pendingGameType = match[1]
+		attrs = append(attrs, slog.String("kind", "gametype_vote"))
+		attrs = append(attrs, slog.String("game_type", p.pendingGameType))
+		p.vote = nil
+	} else if match := reVoteResult.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("vote_result", "vote", match[1], "vote_arg", match[2], "result", match[3])
+		result := strings.ToLower(match[3])
+		if result == "cancelled" {
+			result = "canceled"
+		}
+		attrs = append(attrs, slog.String("kind", "vote_"+result))
+		attrs = append(attrs, slog.String("vote", match[1]))
+		attrs = append(attrs, slog.String("vote_arg", match[2]))
+		if p.vote != nil && p.vote.name == match[1] {
+			player := game.AddPlayer(p.vote.caller, "")
+			attrs = append(attrs, player.Slog("caller"))
+		}
+		p.vote = nil
+	} else if match := reVoteCalled.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("vote_called", "player", match[1], "vote", match[2], "vote_arg", match[3])
+		p.vote = &vote{caller: p.sanitize(game, match[1]), name: match[2], arg: match[3]}
+		player := game.AddPlayer(p.vote.caller, "")
+		attrs = append(attrs, slog.String("kind", "vote_called"))
+		attrs = append(attrs, player.Slog("caller"))
+		attrs = append(attrs, slog.String("vote", p.vote.name))
+		attrs = append(attrs, slog.String("vote_arg", p.vote.arg))
+	} else if match := rePause.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("pause", "player", match[1]+match[2])
+		if !game.Pause(time.Now()) {
+			p.fail(fmt.Errorf("%w: match %s paused twice", ErrStateConflict, game.MatchID()))
+		}
+		attrs = append(attrs, slog.String("kind", "pause"))
+		if name := match[1] + match[2]; name != "" {
+			player := game.AddPlayer(p.sanitize(game, name), "")
+			attrs = append(attrs, player.Slog("player"))
+		}
+	} else if match := reResume.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("resume", "player", match[1]+match[2])
+		paused := game.Resume(time.Now())
+		attrs = append(attrs, slog.String("kind", "resume"))
+		attrs = append(attrs, slog.Duration("paused", paused))
+		if name := match[1] + match[2]; name != "" {
+			player := game.AddPlayer(p.sanitize(game, name), "")
+			attrs = append(attrs, player.Slog("player"))
+		}
+	} else if reOvertime.MatchString(t) {
+		p.decide("overtime")
+		if game.StartOvertime(time.Now()) {
+			attrs = append(attrs, slog.String("kind", "overtime_started"))
+		}
+	} else if reMapRestart.MatchString(t) {
+		p.decide("map_restart")
+		if p.pendingGameType != "" && p.pendingGameType != game.GameType {
+			// clients do not reconnect on a restart: the players and their IPs are kept
+			game.GameType = p.pendingGameType
+			game.Restart()
+			attrs = append(attrs, slog.String("kind", "gametype_change"))
+		} else {
+			// same map and gametype: the players stay but a new match begins
+			game.Restart()
+			attrs = append(attrs, slog.String("kind", "map_restart"))
+		}
+		p.pendingGameType = ""
+		attrs = append(attrs, slog.String("game_type", game.GameType))
+		attrs = append(attrs, slog.String("match_id", game.MatchID()))
+	} else if strings.Contains(t, matchDelimiter) {
+		p.decide("match_end")
+		if !game.HasStarted() {
+			p.fail(fmt.Errorf("%w: match %s ended before it started", ErrStateConflict, game.MatchID()))
+		}
+		// the delimiter is printed again after the scoreboard, only the first one ends the match
+		if previous := game.SetState(MatchPost); previous != MatchPost && game.IsFullGame() {
+			// the summary waits for the scoreboard printed after the delimiter
+			p.scoreboard = &scoreboard{line: t}
+		}
+	} else if match := reNewGame.FindStringSubmatch(t); len(match) > 0 {
+		p.decide("new_game", "game_type", match[1])
+		gameTypeName := match[1]
+		game = p.newGame(gameTypeName)
+		p.game.Store(game)
+		p.pendingGameType = ""
+
+		attrs = append(attrs, slog.String("kind", "new_game"))
+		attrs = append(attrs, slog.String("game_type", game.GameType))
+		attrs = append(attrs, slog.String("match_id", game.MatchID()))
+	} else if match := reSpeak.FindStringSubmatch(t); len(match) > 0 && !playerNameBlacklist[match[1]] {
+		p.decide("chat", "player", match[1], "text", match[2])
+		player := game.AddPlayer(p.sanitize(game, match[1]), "")
+		attrs = append(attrs, player.Slog("player"))
+		attrs = append(attrs, slog.String("text", match[2]))
+	} else {
+		p.fail(ErrUnmatchedLine)
+	}
+	return level, attrs
+}
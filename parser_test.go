@@ -0,0 +1,151 @@
+package warsowlog
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"testing"
+)
+
+// newTestParser returns a parser discarding its records.
+func newTestParser(opts Options) *Parser {
+	if opts.Handler == nil {
+		opts.Handler = slog.NewJSONHandler(io.Discard, nil)
+	}
+	return NewParser(opts)
+}
+
+// parseLines feeds the lines to the parser.
+func parseLines(p *Parser, lines ...string) {
+	ctx := context.Background()
+	for _, line := range lines {
+		p.Parse(ctx, line)
+	}
+}
+
+// snapshotPlayer returns the player of the current game, it fails the test if the player is unknown.
+func snapshotPlayer(t *testing.T, p *Parser, name string) PlayerSnapshot {
+	t.Helper()
+	for _, player := range p.Snapshot().Players {
+		if player.Name == name {
+			return player
+		}
+	}
+	t.Fatalf("player %q is unknown", name)
+	return PlayerSnapshot{}
+}
+
+func TestBotFragsKeptByDefault(t *testing.T) {
+	tests := []struct {
+		policy BotPolicy
+		frags  int
+	}{
+		{"", 1},
+		{BotsKeep, 1},
+		{BotsDropFrags, 0},
+		{BotsExclude, 0},
+	}
+	for _, tt := range tests {
+		t.Run(fmt.Sprintf("%q", tt.policy), func(t *testing.T) {
+			p := newTestParser(Options{Bots: tt.policy})
+			// players without IP are bots, like players of a game the parser attached to
+			parseLines(p, "Sid^7 ate Bob^7's rocket")
+			if got := snapshotPlayer(t, p, "Bob").Scores["Sid"]; got != tt.frags {
+				t.Errorf("expected %d frag, got %d", tt.frags, got)
+			}
+		})
+	}
+}
+
+func TestGametypeVoteKeepsPlayers(t *testing.T) {
+	p := newTestParser(Options{})
+	parseLines(p,
+		"Gametype 'dm' initialized",
+		"A^7 connected from 1.2.3.4:44400",
+		"B^7 connected from 1.2.3.5:44400",
+		"Vote gametype ca passed",
+		"map_restart",
+	)
+	snapshot := p.Snapshot()
+	if snapshot.GameType != "ca" || snapshot.Matches != 2 {
+		t.Errorf("expected a second match in ca, got match %d in %s", snapshot.Matches, snapshot.GameType)
+	}
+	for _, name := range []string{"A", "B"} {
+		if player := snapshotPlayer(t, p, name); player.IsBot || player.IP == "" {
+			t.Errorf("%s did not reconnect and should keep its IP, got %+v", name, player)
+		}
+	}
+}
+
+func TestDelimiterAfterTextEndsMatchOnce(t *testing.T) {
+	ratings, err := LoadRatings(t.TempDir()+"/ratings.json", RatingELO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newTestParser(Options{Ratings: ratings})
+	matchEnds := 0
+	p.OnMatchEnd(func(context.Context, MatchEndEvent) { matchEnds++ })
+	parseLines(p,
+		"Gametype 'dm' initialized",
+		"A^7 connected from 1.2.3.4:44400",
+		"B^7 connected from 1.2.3.5:44400",
+		"All players are ready. Match starting!",
+		"B^7 ate A^7's rocket",
+		matchDelimiter,
+		"Match ended, A wins",
+		matchDelimiter,
+		"A^7: gg",
+	)
+	p.Flush(context.Background())
+
+	if matchEnds != 1 {
+		t.Errorf("expected the match to end once, got %d", matchEnds)
+	}
+	if games := ratings.Get("A").Games; games != 1 {
+		t.Errorf("expected A to be rated for 1 game, got %d", games)
+	}
+}
+
+// benchmarkCorpus returns a team match with the usual mix of a live server: mostly frags and chat,
+// a few unmatched lines.
+func benchmarkCorpus() (header, body []string) {
+	players := []string{"Sid^7", "Monada^7", "P.E.#1^7", "^4Su^7tat^7", "Bob^7", "Eve^7"}
+	header = append(header, "Gametype 'tdm' initialized")
+	for i, name := range players {
+		header = append(header,
+			fmt.Sprintf("%s connected from 1.2.3.%d:44400", name, i),
+			fmt.Sprintf("%s joined the %s team.", name, []string{"ALPHA", "BETA"}[i%2]),
+		)
+	}
+	header = append(header, "All players are ready. Match starting!")
+	for i := range 100 {
+		killer, victim := players[i%len(players)], players[(i*7+3)%len(players)]
+		switch {
+		case i%10 == 0:
+			body = append(body, fmt.Sprintf("Some server noise line %d", i))
+		case i%3 == 0:
+			body = append(body, fmt.Sprintf("%s: gg wp %d", killer, i))
+		case i%2 == 0:
+			body = append(body, fmt.Sprintf("%s ate %s's rocket", victim, killer))
+		default:
+			body = append(body, fmt.Sprintf("%s was instagibbed by %s's instabeam", victim, killer))
+		}
+	}
+	return header, body
+}
+
+func BenchmarkParse(b *testing.B) {
+	header, body := benchmarkCorpus()
+	parser := NewParser(Options{Handler: slog.NewJSONHandler(io.Discard, nil)})
+	ctx := context.Background()
+	for _, line := range header {
+		parser.Parse(ctx, line)
+	}
+
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := range b.N {
+		parser.Parse(ctx, body[i%len(body)])
+	}
+}
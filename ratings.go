@@ -0,0 +1,297 @@
+package warsowlog
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"math"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+const (
+	// DefaultRating is the rating of a player never seen by the ratings
+	DefaultRating = 1500
+	// eloK is the most a player can win or lose in a game
+	eloK = 32
+)
+
+// RatingSystem is the algorithm rating the players.
+type RatingSystem string
+
+const (
+	// RatingELO rates players with a single number
+	RatingELO RatingSystem = "elo"
+	// RatingGlicko2 rates players with a rating, a deviation and a volatility, see glicko2.go
+	RatingGlicko2 RatingSystem = "glicko2"
+)
+
+func ParseRatingSystem(s string) (RatingSystem, error) {
+	switch system := RatingSystem(s); system {
+	case RatingELO, RatingGlicko2:
+		return system, nil
+	default:
+		return "", fmt.Errorf("unknown rating system %q (expected %s or %s)", s, RatingELO, RatingGlicko2)
+	}
+}
+
+// Ratings are the ratings of the players across games, saved in a JSON file, see Options.Ratings.
+// Players are known by their text name so a color change does not reset their rating.
+type Ratings struct {
+	mu      sync.Mutex
+	path    string
+	System  RatingSystem       `json:"system"`
+	Players map[string]*Rating `json:"players"`
+}
+
+// Rating is the rating of a player, the deviation and the volatility are only used by Glicko-2.
+type Rating struct {
+	Rating     float64   `json:"rating"`
+	Deviation  float64   `json:"deviation,omitempty"`
+	Volatility float64   `json:"volatility,omitempty"`
+	Games      int       `json:"games"`
+	LastGame   time.Time `json:"last_game"`
+}
+
+// LoadRatings reads the ratings file, a missing file gives empty ratings that Save creates.
+// A file written by another rating system is refused, its ratings do not mean the same thing.
+func LoadRatings(path string, system RatingSystem) (*Ratings, error) {
+	r := &Ratings{path: path, System: system, Players: make(map[string]*Rating)}
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return r, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	if err := json.Unmarshal(data, r); err != nil {
+		return nil, err
+	}
+	if r.System == "" {
+		// files written before Glicko-2 was offered
+		r.System = RatingELO
+	}
+	if r.System != system {
+		return nil, fmt.Errorf("%s holds %s ratings, not %s", path, r.System, system)
+	}
+	if r.Players == nil {
+		r.Players = make(map[string]*Rating)
+	}
+	return r, nil
+}
+
+// Save writes the ratings back to their file, through a temporary file so a crash does not lose them.
+func (r *Ratings) Save() error {
+	r.mu.Lock()
+	data, err := json.MarshalIndent(r, "", "  ")
+	r.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	tmp := r.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, r.path)
+}
+
+// Get returns the rating of the player, with DefaultRating if the player is unknown.
+func (r *Ratings) Get(textName string) Rating {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if rating, ok := r.Players[textName]; ok {
+		return *rating
+	}
+	return Rating{Rating: DefaultRating}
+}
+
+// Standing is a side of a rated game: the text names of its players and its rank, 0 for the winner.
+// Sides with the same rank drew.
+type Standing struct {
+	Players []string
+	Rank    int
+}
+
+// Update rates a game from its final standings and returns the change of each player.
+// Every player duels every player of the other sides, won by the best ranked side; teammates do not duel.
+func (r *Ratings) Update(standings []Standing) map[string]float64 {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// a duel outcome compares scores, the best rank is the highest score
+	scores := make(map[string]int)
+	sides := make(map[string]int)
+	for i, standing := range standings {
+		for _, name := range standing.Players {
+			scores[name] = -standing.Rank
+			sides[name] = i
+		}
+	}
+	if len(lo.Uniq(lo.Values(sides))) < 2 {
+		return map[string]float64{}
+	}
+	now := time.Now()
+	for name := range scores {
+		if _, ok := r.Players[name]; !ok {
+			r.Players[name] = &Rating{Rating: DefaultRating, LastGame: now}
+			if r.System == RatingGlicko2 {
+				r.Players[name].Deviation = glickoDeviation
+				r.Players[name].Volatility = glickoVolatility
+			}
+		}
+	}
+	var updated map[string]Rating
+	if r.System == RatingGlicko2 {
+		updated = r.glicko2(scores, sides, now)
+	} else {
+		updated = r.elo(scores, sides)
+	}
+	deltas := make(map[string]float64, len(updated))
+	for name, rating := range updated {
+		deltas[name] = rating.Rating - r.Players[name].Rating
+		rating.Games = r.Players[name].Games + 1
+		rating.LastGame = now
+		*r.Players[name] = rating
+	}
+	return deltas
+}
+
+// elo returns the new ratings of the players, from the ratings before the game.
+func (r *Ratings) elo(scores map[string]int, sides map[string]int) map[string]Rating {
+	updated := make(map[string]Rating, len(scores))
+	for name, score := range scores {
+		// the duels of a player weigh as much as a single game whatever the number of opponents
+		k := eloK / float64(opponents(name, sides))
+		rating := *r.Players[name]
+		for opponent, opponentScore := range scores {
+			if sides[opponent] == sides[name] {
+				continue
+			}
+			expected := 1 / (1 + math.Pow(10, (r.Players[opponent].Rating-r.Players[name].Rating)/400))
+			rating.Rating += k * (duel(score, opponentScore) - expected)
+		}
+		updated[name] = rating
+	}
+	return updated
+}
+
+// opponents returns the number of players that are not on the side of the player.
+func opponents(name string, sides map[string]int) int {
+	n := 0
+	for _, side := range sides {
+		if side != sides[name] {
+			n++
+		}
+	}
+	return n
+}
+
+// duel returns the outcome of a duel for the first player: 1 for a win, 0.5 for a draw and 0 for a loss.
+func duel(score, opponentScore int) float64 {
+	switch {
+	case score > opponentScore:
+		return 1
+	case score < opponentScore:
+		return 0
+	default:
+		return 0.5
+	}
+}
+
+// Merge moves the ratings of the aliases to the canonical name, for a player known under several names.
+// The rating of the most played name is kept, the games are summed. It returns the ratings changed
+// by the merge as they were before, nil for the names without rating, so Restore can undo it.
+// An alias given twice is merged once, an alias naming the canonical player is refused.
+func (r *Ratings) Merge(canonical string, aliases ...string) (map[string]*Rating, error) {
+	for _, alias := range aliases {
+		if alias == canonical {
+			return nil, fmt.Errorf("%q cannot be an alias of itself", alias)
+		}
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	before := make(map[string]*Rating, len(aliases)+1)
+	var best *Rating
+	games, lastGame := 0, time.Time{}
+	for _, name := range append([]string{canonical}, aliases...) {
+		if _, seen := before[name]; seen {
+			continue
+		}
+		rating, ok := r.Players[name]
+		if !ok {
+			before[name] = nil
+			continue
+		}
+		before[name] = rating
+		if best == nil || rating.Games > best.Games {
+			best = rating
+		}
+		games += rating.Games
+		if rating.LastGame.After(lastGame) {
+			lastGame = rating.LastGame
+		}
+		delete(r.Players, name)
+	}
+	if best != nil {
+		merged := *best
+		merged.Games = games
+		merged.LastGame = lastGame
+		r.Players[canonical] = &merged
+	}
+	return before, nil
+}
+
+// Restore puts back the ratings returned by Merge.
+func (r *Ratings) Restore(before map[string]*Rating) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for name, rating := range before {
+		if rating == nil {
+			delete(r.Players, name)
+			continue
+		}
+		restored := *rating
+		r.Players[name] = &restored
+	}
+}
+
+// rate updates the ratings with the sides of a ranked game and returns the ratings attribute of the summary.
+// A draw leaves the drawing sides with the same rank and a forfeit puts the sides that left below the winner
+// whatever their score. Bots are not rated, their names do not designate the same opponent from one server to another.
+func (p *Parser) rate(game *Game, result Result, players []*Player) slog.Attr {
+	standings := make([]Standing, 0, len(result.Sides))
+	for i, side := range result.Sides {
+		standing := Standing{Rank: i}
+		if i > 0 && side.Score == result.Sides[i-1].Score && side.Forfeited == result.Sides[i-1].Forfeited {
+			standing.Rank = standings[len(standings)-1].Rank
+		}
+		for _, name := range side.Players {
+			if player := game.players[name]; !player.IsBot() {
+				standing.Players = append(standing.Players, player.TextName)
+			}
+		}
+		standings = append(standings, standing)
+	}
+	deltas := p.opts.Ratings.Update(standings)
+	rated := make([]slog.Attr, 0, len(deltas))
+	for _, player := range players {
+		delta, ok := deltas[player.TextName]
+		if !ok {
+			continue
+		}
+		rating := p.opts.Ratings.Get(player.TextName)
+		attrs := []slog.Attr{
+			slog.Float64("rating", math.Round(rating.Rating)),
+			slog.Float64("delta", math.Round(delta*10)/10),
+		}
+		if rating.Deviation > 0 {
+			attrs = append(attrs, slog.Float64("deviation", math.Round(rating.Deviation)))
+		}
+		rated = append(rated, slog.Attr{Key: player.Name, Value: slog.GroupValue(attrs...)})
+	}
+	return slog.Attr{Key: "ratings", Value: slog.GroupValue(rated...)}
+}
@@ -0,0 +1,77 @@
+package warsowlog
+
+import (
+	"context"
+	"math"
+	"testing"
+)
+
+func TestMergeDuplicateAlias(t *testing.T) {
+	r := &Ratings{System: RatingELO, Players: map[string]*Rating{
+		"Sid":  {Rating: 1600, Games: 10},
+		"Sid2": {Rating: 1400, Games: 2},
+	}}
+	before, err := r.Merge("Sid", "Sid2", "Sid2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := r.Players["Sid"]; got.Rating != 1600 || got.Games != 12 {
+		t.Errorf("expected rating 1600 over 12 games, got %v over %d games", got.Rating, got.Games)
+	}
+	if before["Sid2"] == nil || before["Sid2"].Games != 2 {
+		t.Fatalf("expected the rating of Sid2 before the merge, got %v", before["Sid2"])
+	}
+
+	r.Restore(before)
+	if r.Players["Sid"].Games != 10 || r.Players["Sid2"].Games != 2 {
+		t.Errorf("expected the merge to be undone, got %d and %d games", r.Players["Sid"].Games, r.Players["Sid2"].Games)
+	}
+}
+
+func TestMergeCanonicalAlias(t *testing.T) {
+	r := &Ratings{System: RatingELO, Players: map[string]*Rating{"Sid": {Rating: 1600, Games: 10}}}
+	if _, err := r.Merge("Sid", "Sid"); err == nil {
+		t.Fatal("expected an error merging a player into itself")
+	}
+	if got := r.Players["Sid"]; got == nil || got.Games != 10 {
+		t.Errorf("expected Sid to be left untouched, got %v", got)
+	}
+}
+
+func TestUpdateTeams(t *testing.T) {
+	r := &Ratings{System: RatingELO, Players: map[string]*Rating{}}
+	deltas := r.Update([]Standing{{Players: []string{"A", "B"}, Rank: 0}, {Players: []string{"C", "D"}, Rank: 1}})
+	// teammates do not duel, each player wins or loses both duels against the other team
+	for name, want := range map[string]float64{"A": 16, "B": 16, "C": -16, "D": -16} {
+		if got := deltas[name]; math.Abs(got-want) > 1e-9 {
+			t.Errorf("delta of %s = %v, want %v", name, got, want)
+		}
+	}
+
+	if deltas := r.Update([]Standing{{Players: []string{"A", "B"}}}); len(deltas) != 0 {
+		t.Errorf("a single side has no opponent, got %v", deltas)
+	}
+}
+
+func TestRateForfeit(t *testing.T) {
+	ratings, err := LoadRatings(t.TempDir()+"/ratings.json", RatingELO)
+	if err != nil {
+		t.Fatal(err)
+	}
+	p := newTestParser(Options{Ratings: ratings})
+	parseLines(p,
+		"Gametype 'duel' initialized",
+		"A^7 connected from 1.2.3.4:44400",
+		"B^7 connected from 1.2.3.5:44400",
+		"All players are ready. Match starting!",
+		"A^7 ate B^7's rocket",
+		"A^7 ate B^7's rocket",
+		"B^7 disconnected",
+		matchDelimiter,
+	)
+	p.Flush(context.Background())
+
+	if a, b := ratings.Get("A").Rating, ratings.Get("B").Rating; a <= DefaultRating || b >= DefaultRating {
+		t.Errorf("B left and forfeited despite its frags, got A %v and B %v", a, b)
+	}
+}
@@ -0,0 +1,48 @@
+package warsowlog
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/fabienjuif/warsowlog/color"
+)
+
+// NameSanitizer cleans a player name extracted from a console line.
+// known tells if a name is already in the roster of the current game.
+type NameSanitizer func(name string, known func(string) bool) string
+
+// ParseNameSanitizer returns the sanitizer for the given mode: default or strict.
+func ParseNameSanitizer(mode string) (NameSanitizer, error) {
+	switch mode {
+	case "default":
+		return func(name string, _ func(string) bool) string { return sanitizePlayer(name) }, nil
+	case "strict":
+		return strictSanitizePlayer, nil
+	default:
+		return nil, fmt.Errorf("unknown sanitizer %q (expected default or strict)", mode)
+	}
+}
+
+// sanitizePlayer cleans the player name by removing unwanted characters
+// ^4Su^7ta^1t^7 becomes ^4Su^7ta^1t
+// escaped carets are not color codes: k^^7 is kept as is
+func sanitizePlayer(name string) string {
+	trimmed := strings.TrimSpace(name)
+
+	stripped, _, _ := color.CutTrailing(trimmed)
+	return stripped
+}
+
+// strictSanitizePlayer only strips the ^7 the server appends after names,
+// and keeps the name untouched when it is already known in the roster.
+// Names legitimately ending with a color code or a caret are kept as is.
+func strictSanitizePlayer(name string, known func(string) bool) string {
+	trimmed := strings.TrimSpace(name)
+	if known(trimmed) {
+		return trimmed
+	}
+	if stripped, code, ok := color.CutTrailing(trimmed); ok && code.Color == 7 && stripped != "" {
+		return stripped
+	}
+	return trimmed
+}
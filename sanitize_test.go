@@ -0,0 +1,54 @@
+package warsowlog
+
+import "testing"
+
+func TestNameSanitizers(t *testing.T) {
+	roster := map[string]bool{"Bob^7": true, "k^9": true}
+	known := func(name string) bool { return roster[name] }
+
+	tests := []struct {
+		name     string
+		input    string
+		fallback string
+		strict   string
+	}{
+		{"trailing ^7", "Sid^7", "Sid", "Sid"},
+		{"surrounding spaces", " Sid^7 ", "Sid", "Sid"},
+		{"inner codes are kept", "^4Su^7ta^1t^7", "^4Su^7ta^1t", "^4Su^7ta^1t"},
+		{"no code", "Sid", "Sid", "Sid"},
+		{"other trailing code", "k^9", "k", "k^9"},
+		{"other trailing code before ^7", "k^9^7", "k^9", "k^9"},
+		{"digit after a code", "k^99", "k^99", "k^99"},
+		{"escaped caret before a digit", "k^^7", "k^^7", "k^^7"},
+		{"escaped caret then ^7", "k^^7^7", "k^^7", "k^^7"},
+		{"trailing escaped caret", "k^^", "k^^", "k^^"},
+		{"escaped caret only", "^^^7", "^^", "^^"},
+		{"only a code", "^7", "", "^7"},
+		{"known name ending with ^7", "Bob^7", "Bob", "Bob^7"},
+	}
+
+	fallback, err := ParseNameSanitizer("default")
+	if err != nil {
+		t.Fatal(err)
+	}
+	strict, err := ParseNameSanitizer("strict")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := fallback(tt.input, known); got != tt.fallback {
+				t.Errorf("default(%q) = %q, want %q", tt.input, got, tt.fallback)
+			}
+			if got := strict(tt.input, known); got != tt.strict {
+				t.Errorf("strict(%q) = %q, want %q", tt.input, got, tt.strict)
+			}
+		})
+	}
+}
+
+func TestParseNameSanitizerUnknown(t *testing.T) {
+	if _, err := ParseNameSanitizer("lenient"); err == nil {
+		t.Error("expected an error for an unknown sanitizer")
+	}
+}
@@ -0,0 +1,96 @@
+package warsowlog
+
+import (
+	"log/slog"
+	"strings"
+	"time"
+)
+
+// ScoreboardRow is a line of the scoreboard printed by the server at the end of a match.
+type ScoreboardRow struct {
+	Name  string
+	Score int
+	Ping  int
+	// Time is how long the player played the match
+	Time time.Duration
+}
+
+// scoreboard collects the end of match block, between the delimiter closing the match and the next one.
+type scoreboard struct {
+	// line is the delimiter that opened the block, it is the message of the summary
+	line string
+	// read is true once a header or a row was read, so a delimiter closes the block
+	read bool
+	rows []ScoreboardRow
+}
+
+// readScoreboard handles a line following the end of match delimiter, it must be called within a command of the given game.
+// It returns true if the line belongs to the scoreboard, otherwise the scoreboard is closed
+// and the line must be parsed as usual after the summary.
+func (p *Parser) readScoreboard(game *Game, t string) ([]record, bool) {
+	board := p.scoreboard
+	if reScoreboardHeader.MatchString(t) {
+		board.read = true
+		return []record{{level: slog.LevelInfo, msg: t, attrs: []slog.Attr{slog.String("kind", "scoreboard")}, matchID: game.MatchID()}}, true
+	}
+	if row, ok := parseScoreboardRow(t); ok {
+		board.read = true
+		board.rows = append(board.rows, row)
+		return []record{{level: slog.LevelInfo, msg: t, attrs: []slog.Attr{slog.String("kind", "scoreboard")}, matchID: game.MatchID()}}, true
+	}
+	summary := p.closeScoreboard(game)
+	// the delimiter closing the scoreboard does not end another match
+	return []record{summary}, board.read && strings.Contains(t, matchDelimiter)
+}
+
+// closeScoreboard builds the summary of the ended match with its scoreboard, it must be called within a command of the given game.
+func (p *Parser) closeScoreboard(game *Game) record {
+	board := p.scoreboard
+	p.scoreboard = nil
+	level, attrs := p.summarize(game)
+	attrs = append(attrs, p.reconcileScores(game, board.rows)...)
+	result, hasResult := ComputeResult(game, ScoringFor(game.GameType), p.summarized(game))
+	p.queue(MatchEndEvent{
+		Game:       game.snapshot(),
+		Result:     result,
+		HasResult:  hasResult,
+		Unranked:   p.isUnranked(game),
+		Scoreboard: board.rows,
+	})
+	return record{level: level, msg: board.line, attrs: attrs, matchID: game.MatchID()}
+}
+
+// reconcileScores returns the official scores of the scoreboard and the players whose score
+// differs from the one derived from the frags.
+func (p *Parser) reconcileScores(game *Game, rows []ScoreboardRow) []slog.Attr {
+	if len(rows) == 0 {
+		return nil
+	}
+	official := make([]slog.Attr, 0, len(rows))
+	mismatches := make([]slog.Attr, 0)
+	for _, row := range rows {
+		name := p.sanitize(game, row.Name)
+		official = append(official, slog.Group(
+			name,
+			slog.Int("score", row.Score),
+			slog.Int("ping", row.Ping),
+			slog.Int64("time_ms", row.Time.Milliseconds()),
+		))
+		derived := 0
+		if player, ok := game.players[name]; ok {
+			derived = player.Total()
+		}
+		if derived != row.Score {
+			mismatches = append(mismatches, slog.Group(
+				name,
+				slog.Int("official", row.Score),
+				slog.Int("derived", derived),
+			))
+		}
+	}
+	attrs := []slog.Attr{{Key: "official_scores", Value: slog.GroupValue(official...)}}
+	if len(mismatches) > 0 {
+		attrs = append(attrs, slog.Attr{Key: "score_mismatches", Value: slog.GroupValue(mismatches...)})
+	}
+	return attrs
+}
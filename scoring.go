@@ -0,0 +1,286 @@
+package warsowlog
+
+import (
+	"math"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/samber/lo"
+)
+
+// ScoringStrategy decides how players are ranked at the end of a game, it depends on the gametype.
+type ScoringStrategy interface {
+	// Name is added to the game summary so consumers know how the winner was picked
+	Name() string
+	// Score returns the score of the player used to rank it, higher is better
+	// NoScore is returned for players that can not be ranked (a race without finish time)
+	Score(g *Game, p *Player) int
+}
+
+// NoScore is the score of a player that can not be ranked by a strategy.
+const NoScore = math.MinInt32
+
+// scoreFormatter is implemented by strategies whose scores do not read as plain numbers.
+type scoreFormatter interface {
+	FormatScore(score int) string
+}
+
+func formatScore(s ScoringStrategy, score int) string {
+	if f, ok := s.(scoreFormatter); ok {
+		return f.FormatScore(score)
+	}
+	return strconv.Itoa(score)
+}
+
+// scoringStrategies maps a gametype to its strategy, unknown gametypes are frag-based.
+var scoringStrategies = map[string]ScoringStrategy{
+	"dm":   fragScoring{},
+	"duel": fragScoring{},
+	"tdm":  fragScoring{},
+	"ctf":  captureScoring{},
+	"race": raceScoring{},
+	"ca":   roundScoring{},
+	"bomb": roundScoring{},
+}
+
+func ScoringFor(gameType string) ScoringStrategy {
+	if s, ok := scoringStrategies[gameType]; ok {
+		return s
+	}
+	return fragScoring{}
+}
+
+// Ranking returns the given players sorted from the best to the worst according to the strategy.
+// Ties are broken by total score, then by name so the ranking is stable from one run to another.
+func Ranking(g *Game, s ScoringStrategy, players []*Player) []*Player {
+	ranked := make([]*Player, len(players))
+	copy(ranked, players)
+	sort.Slice(ranked, func(i, j int) bool {
+		si, sj := s.Score(g, ranked[i]), s.Score(g, ranked[j])
+		if si != sj {
+			return si > sj
+		}
+		if ti, tj := ranked[i].Total(), ranked[j].Total(); ti != tj {
+			return ti > tj
+		}
+		return ranked[i].Name < ranked[j].Name
+	})
+	return ranked
+}
+
+// fragScoring ranks players by their total score (frags minus suicides).
+type fragScoring struct{}
+
+func (fragScoring) Name() string { return "frags" }
+
+func (fragScoring) Score(_ *Game, p *Player) int { return p.Total() }
+
+// Outcome tells how a game ended.
+type Outcome string
+
+const (
+	// OutcomeWin is a game with a single best player
+	OutcomeWin Outcome = "win"
+	// OutcomeDraw is a game where the best players have the same score, there is no winner
+	OutcomeDraw Outcome = "draw"
+	// OutcomeForfeit is a game where all the opponents of the winner disconnected before the end
+	OutcomeForfeit Outcome = "forfeit"
+)
+
+// captureScoring ranks players by their flag captures.
+type captureScoring struct{}
+
+func (captureScoring) Name() string { return "captures" }
+
+func (captureScoring) Score(_ *Game, p *Player) int { return p.Captures }
+
+// roundScoring ranks players by the rounds won by them or by their team.
+type roundScoring struct{}
+
+func (roundScoring) Name() string { return "rounds" }
+
+func (roundScoring) Score(g *Game, p *Player) int {
+	wins := g.RoundWins(p.Name)
+	if isTeam(p.Team) {
+		wins += g.RoundWins(p.Team)
+	}
+	return wins
+}
+
+// raceScoring ranks players by their best race time, lower is better.
+// The score is the opposite of the time in milliseconds.
+type raceScoring struct{}
+
+func (raceScoring) Name() string { return "time" }
+
+func (raceScoring) Score(_ *Game, p *Player) int {
+	if p.BestTime == 0 {
+		return NoScore
+	}
+	return -int(p.BestTime.Milliseconds())
+}
+
+func (raceScoring) FormatScore(score int) string {
+	if score == NoScore {
+		return "none"
+	}
+	return (time.Duration(-score) * time.Millisecond).String()
+}
+
+// Result is the outcome of a game according to its scoring strategy.
+// In team gametypes the sides of the game are the teams, otherwise they are the players.
+type Result struct {
+	Outcome Outcome
+	// Winner is the winning team or player, empty on a draw
+	Winner string
+	Loser  string
+	// FinalScore is the score of the winner against the score of the runner-up, e.g. "12-8"
+	FinalScore string
+	// Margin is how far the winner is ahead of the runner-up, it is zero on a draw
+	// and on a forfeit won from behind
+	Margin int
+	// Sides are the teams and the players without team, from the winner to the last one
+	Sides []Side
+}
+
+// Side is a team, or a player without team, competing in a game.
+type Side struct {
+	// Name is the team or the player name
+	Name string
+	// Players are the names of the players of the side
+	Players []string
+	Score   int
+	// Forfeited is true if every player of the side left before the end of a forfeit
+	Forfeited bool
+	// left is true if every player of the side is disconnected
+	left bool
+}
+
+// teamScorer is implemented by strategies whose team score is not the sum of the scores of its players.
+type teamScorer interface {
+	TeamScore(g *Game, team string, players []*Player) int
+}
+
+// TeamScore counts the rounds won by the team and by its players.
+func (roundScoring) TeamScore(g *Game, team string, players []*Player) int {
+	wins := g.RoundWins(team)
+	for _, p := range players {
+		wins += g.RoundWins(p.Name)
+	}
+	return wins
+}
+
+// TeamScore is the best time of the players of the team.
+func (s raceScoring) TeamScore(g *Game, _ string, players []*Player) int {
+	best := NoScore
+	for _, p := range players {
+		best = max(best, s.Score(g, p))
+	}
+	return best
+}
+
+// sideOf returns the side of the player: its team if it plays in a team, otherwise itself.
+func sideOf(p *Player) string {
+	if isTeam(p.Team) {
+		return p.Team
+	}
+	return p.Name
+}
+
+// rankSides groups the players by side and sorts the sides from the best to the worst according to the strategy.
+// Ties are broken by total score, then by name like in Ranking.
+func rankSides(g *Game, s ScoringStrategy, players []*Player) []Side {
+	members := make(map[string][]*Player)
+	names := make([]string, 0, len(players))
+	for _, p := range Ranking(g, s, players) {
+		side := sideOf(p)
+		if _, ok := members[side]; !ok {
+			names = append(names, side)
+		}
+		members[side] = append(members[side], p)
+	}
+	sides := make([]Side, 0, len(names))
+	totals := make(map[string]int, len(names))
+	for _, name := range names {
+		side := Side{Name: name, left: true}
+		for _, p := range members[name] {
+			side.Players = append(side.Players, p.Name)
+			side.left = side.left && !p.connected
+			totals[name] += p.Total()
+		}
+		side.Score = teamScore(g, s, name, members[name])
+		sides = append(sides, side)
+	}
+	sort.SliceStable(sides, func(i, j int) bool {
+		if si, sj := sides[i].Score, sides[j].Score; si != sj {
+			return si > sj
+		}
+		if ti, tj := totals[sides[i].Name], totals[sides[j].Name]; ti != tj {
+			return ti > tj
+		}
+		return sides[i].Name < sides[j].Name
+	})
+	return sides
+}
+
+// teamScore returns the score of the side, the sum of the scores of its players unless the strategy
+// knows better. A player alone is scored as a player.
+func teamScore(g *Game, s ScoringStrategy, name string, players []*Player) int {
+	if len(players) == 1 && players[0].Name == name {
+		return s.Score(g, players[0])
+	}
+	if ts, ok := s.(teamScorer); ok {
+		return ts.TeamScore(g, name, players)
+	}
+	score, scored := 0, false
+	for _, p := range players {
+		if v := s.Score(g, p); v != NoScore {
+			score += v
+			scored = true
+		}
+	}
+	if !scored {
+		return NoScore
+	}
+	return score
+}
+
+// ComputeResult returns the result of the game between the given players, team against team in team gametypes.
+// It returns false if there are not enough sides to have a winner and a loser.
+func ComputeResult(g *Game, s ScoringStrategy, players []*Player) (Result, bool) {
+	sides := rankSides(g, s, players)
+	if len(sides) < 2 {
+		return Result{}, false
+	}
+	remaining := lo.Filter(sides, func(side Side, _ int) bool { return !side.left })
+	forfeit := len(remaining) == 1
+	if forfeit {
+		// the only side left wins whatever the scores, the others forfeited
+		sides = append(remaining, lo.Filter(sides, func(side Side, _ int) bool { return side.left })...)
+		for i := range sides[1:] {
+			sides[i+1].Forfeited = true
+		}
+	}
+
+	first := sides[0].Score
+	second := sides[1].Score
+	result := Result{
+		Outcome:    OutcomeWin,
+		Winner:     sides[0].Name,
+		Loser:      sides[len(sides)-1].Name,
+		FinalScore: formatScore(s, first) + "-" + formatScore(s, second),
+		Sides:      sides,
+	}
+	if first != NoScore && second != NoScore {
+		result.Margin = max(first-second, 0)
+	}
+	if forfeit {
+		result.Outcome = OutcomeForfeit
+	} else if first == second {
+		result.Outcome = OutcomeDraw
+		result.Winner = ""
+		result.Loser = ""
+	}
+	return result, true
+}
@@ -0,0 +1,115 @@
+package warsowlog
+
+import (
+	"fmt"
+	"testing"
+)
+
+// teamGame returns a game whose players are connected and joined the given teams, by name.
+func teamGame(gameType string, teams map[string]string) *Game {
+	g := NewGame(gameType)
+	for name, team := range teams {
+		g.AddPlayer(name, "1.2.3.4").JoinTeam(team)
+	}
+	return g
+}
+
+func TestComputeResultTeams(t *testing.T) {
+	tests := []struct {
+		name  string
+		game  func() *Game
+		want  Result
+		sides [][]string
+	}{
+		{
+			name: "ca",
+			game: func() *Game {
+				g := teamGame("ca", map[string]string{"A": "ALPHA", "B": "ALPHA", "C": "BETA", "D": "BETA"})
+				g.EndRound(TeamAlpha)
+				g.StartRound(0)
+				g.EndRound(TeamAlpha)
+				return g
+			},
+			want:  Result{Outcome: OutcomeWin, Winner: TeamAlpha, Loser: TeamBeta, FinalScore: "2-0", Margin: 2},
+			sides: [][]string{{"A", "B"}, {"C", "D"}},
+		},
+		{
+			name: "tdm",
+			game: func() *Game {
+				g := teamGame("tdm", map[string]string{"A": "ALPHA", "B": "ALPHA", "C": "BETA"})
+				g.players["C"].Frag("A", "rocket")
+				g.players["C"].Frag("B", "rocket")
+				g.players["A"].Frag("C", "rocket")
+				return g
+			},
+			want:  Result{Outcome: OutcomeWin, Winner: TeamBeta, Loser: TeamAlpha, FinalScore: "2-1", Margin: 1},
+			sides: [][]string{{"C"}, {"A", "B"}},
+		},
+		{
+			name: "dm",
+			game: func() *Game {
+				g := teamGame("dm", map[string]string{"A": "PLAYERS", "B": "PLAYERS", "C": "PLAYERS"})
+				g.players["B"].Frag("A", "rocket")
+				return g
+			},
+			want:  Result{Outcome: OutcomeWin, Winner: "B", Loser: "C", FinalScore: "1-0", Margin: 1},
+			sides: [][]string{{"B"}, {"A"}, {"C"}},
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			g := tt.game()
+			result, ok := ComputeResult(g, ScoringFor(g.GameType), g.Playing())
+			if !ok {
+				t.Fatal("expected a result")
+			}
+			if result.Outcome != tt.want.Outcome || result.Winner != tt.want.Winner || result.Loser != tt.want.Loser ||
+				result.FinalScore != tt.want.FinalScore || result.Margin != tt.want.Margin {
+				t.Errorf("got %s won by %q over %q %s (margin %d), want %s won by %q over %q %s (margin %d)",
+					result.Outcome, result.Winner, result.Loser, result.FinalScore, result.Margin,
+					tt.want.Outcome, tt.want.Winner, tt.want.Loser, tt.want.FinalScore, tt.want.Margin)
+			}
+			if len(result.Sides) != len(tt.sides) {
+				t.Fatalf("expected %d sides, got %v", len(tt.sides), result.Sides)
+			}
+			for i, side := range result.Sides {
+				if fmt.Sprint(side.Players) != fmt.Sprint(tt.sides[i]) {
+					t.Errorf("side %d: expected players %v, got %v", i, tt.sides[i], side.Players)
+				}
+			}
+		})
+	}
+}
+
+func TestComputeResultTeammatesOnly(t *testing.T) {
+	g := teamGame("ca", map[string]string{"A": "ALPHA", "B": "ALPHA"})
+	if _, ok := ComputeResult(g, ScoringFor(g.GameType), g.Playing()); ok {
+		t.Error("a single team has no opponent to win against")
+	}
+}
+
+func TestComputeResultForfeit(t *testing.T) {
+	g := teamGame("tdm", map[string]string{"A": "ALPHA", "B": "ALPHA", "C": "BETA", "D": "BETA"})
+	g.players["A"].Frag("C", "rocket")
+	for range 3 {
+		g.players["C"].Frag("A", "rocket")
+	}
+	g.players["C"].Disconnect("")
+
+	result, _ := ComputeResult(g, ScoringFor(g.GameType), g.Playing())
+	if result.Outcome != OutcomeWin || result.Winner != TeamBeta {
+		t.Errorf("BETA still has a player and leads, got %s won by %q", result.Outcome, result.Winner)
+	}
+
+	g.players["D"].Disconnect("")
+	result, _ = ComputeResult(g, ScoringFor(g.GameType), g.Playing())
+	if result.Outcome != OutcomeForfeit || result.Winner != TeamAlpha || result.Loser != TeamBeta {
+		t.Fatalf("every BETA player left, got %s won by %q over %q", result.Outcome, result.Winner, result.Loser)
+	}
+	if result.FinalScore != "1-3" || result.Margin != 0 {
+		t.Errorf("expected 1-3 without margin for a forfeit won from behind, got %s with margin %d", result.FinalScore, result.Margin)
+	}
+	if result.Sides[0].Forfeited || !result.Sides[1].Forfeited {
+		t.Errorf("expected BETA to have forfeited, got %+v", result.Sides)
+	}
+}
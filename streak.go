@@ -0,0 +1,89 @@
+package warsowlog
+
+import (
+	"log/slog"
+	"time"
+)
+
+// multikillNames names the multikills by their number of frags, more frags are a mega kill.
+var multikillNames = map[int]string{2: "double", 3: "triple"}
+
+func multikillName(frags int) string {
+	if name, ok := multikillNames[frags]; ok {
+		return name
+	}
+	return "mega"
+}
+
+// streak is the state of the frag streaks of a player in the current match.
+type streak struct {
+	// lastFragAt is when the player fragged for the last time, zero if they did not yet
+	lastFragAt time.Time
+	// multi counts the frags of the current multikill
+	multi int
+	// spree counts the frags since the last death
+	spree int
+}
+
+// fragStreak counts a frag in the streaks of the player and returns the number of frags of the current
+// multikill (1 if the previous frag is older than the window) and of the current spree.
+func (p *Player) fragStreak(now time.Time, window time.Duration) (int, int) {
+	if window <= 0 || p.streak.lastFragAt.IsZero() || now.Sub(p.streak.lastFragAt) > window {
+		p.streak.multi = 0
+	}
+	p.streak.lastFragAt = now
+	p.streak.multi++
+	p.streak.spree++
+	// a chain is counted once with its final size: a triple kill is not also a double kill
+	if p.streak.multi > 2 {
+		p.Multikills[multikillName(p.streak.multi-1)]--
+	}
+	if p.streak.multi >= 2 {
+		p.Multikills[multikillName(p.streak.multi)]++
+	}
+	p.BestSpree = max(p.BestSpree, p.streak.spree)
+	return p.streak.multi, p.streak.spree
+}
+
+// dieStreak ends the spree of the player and returns its number of frags.
+func (p *Player) dieStreak() int {
+	spree := p.streak.spree
+	p.streak = streak{}
+	return spree
+}
+
+// trackStreaks updates the streaks of a counted frag, the killer is nil for environmental deaths, suicides
+// and team kills. The multikills and sprees are emitted as extra records, each frag of a multikill emits
+// one with the size reached so far. It must be called within a command of the given game.
+func (p *Parser) trackStreaks(game *Game, killer, victim *Player) {
+	if spree := victim.dieStreak(); p.opts.SpreeFrags > 0 && spree >= p.opts.SpreeFrags {
+		attrs := []slog.Attr{
+			slog.String("kind", "spree_end"),
+			victim.Slog("player"),
+			slog.Int("spree", spree),
+		}
+		if killer != nil {
+			attrs = append(attrs, killer.Slog("killer"))
+		}
+		p.extra = append(p.extra, record{level: slog.LevelInfo, msg: p.line, attrs: attrs, matchID: game.MatchID()})
+	}
+	if killer == nil {
+		return
+	}
+	multi, spree := killer.fragStreak(time.Now(), p.opts.MultikillWindow)
+	if multi >= 2 {
+		p.extra = append(p.extra, record{level: slog.LevelInfo, msg: p.line, matchID: game.MatchID(), attrs: []slog.Attr{
+			slog.String("kind", "multikill"),
+			slog.String("multikill", multikillName(multi)),
+			slog.Int("frags", multi),
+			killer.Slog("player"),
+		}})
+	}
+	if p.opts.SpreeFrags > 0 && spree == p.opts.SpreeFrags {
+		p.extra = append(p.extra, record{level: slog.LevelInfo, msg: p.line, matchID: game.MatchID(), attrs: []slog.Attr{
+			slog.String("kind", "spree_start"),
+			slog.Int("spree", spree),
+			killer.Slog("player"),
+		}})
+	}
+}
@@ -0,0 +1,57 @@
+package warsowlog
+
+import (
+	"context"
+	"io"
+	"log/slog"
+	"testing"
+	"time"
+)
+
+func TestMultikillsCountFinalSize(t *testing.T) {
+	p := NewPlayer("Sid")
+	now := time.Now()
+	for i := range 3 {
+		p.fragStreak(now.Add(time.Duration(i)*time.Second), 3*time.Second)
+	}
+	// the next chain is a mega kill
+	for i := range 5 {
+		p.fragStreak(now.Add(time.Minute+time.Duration(i)*time.Second), 3*time.Second)
+	}
+	want := map[string]int{"double": 0, "triple": 1, "mega": 1}
+	for name, n := range want {
+		if p.Multikills[name] != n {
+			t.Errorf("expected %d %s kills, got %d", n, name, p.Multikills[name])
+		}
+	}
+}
+
+func TestTeamKillEndsSpree(t *testing.T) {
+	parser := NewParser(Options{Handler: slog.NewJSONHandler(io.Discard, nil), SpreeFrags: 2, MultikillWindow: time.Second})
+	ctx := context.Background()
+	for _, line := range []string{
+		"Sid^7 connected from 1.2.3.4:1",
+		"Bob^7 connected from 1.2.3.5:1",
+		"Ann^7 connected from 1.2.3.6:1",
+		"Sid^7 joined the ALPHA team.",
+		"Bob^7 joined the BETA team.",
+		"Ann^7 joined the ALPHA team.",
+		"Bob^7 ate Sid^7's rocket",
+		"Bob^7 ate Sid^7's rocket",
+		"Sid^7 ate Ann^7's rocket",
+	} {
+		parser.Parse(ctx, line)
+	}
+	parser.game.Load().Apply(func(g *Game) {
+		sid := g.players["Sid"]
+		if sid.BestSpree != 2 {
+			t.Errorf("expected a best spree of 2, got %d", sid.BestSpree)
+		}
+		if sid.streak.spree != 0 {
+			t.Errorf("the team kill should end the spree, still at %d", sid.streak.spree)
+		}
+		if sid.Deaths != 1 {
+			t.Errorf("expected 1 death, got %d", sid.Deaths)
+		}
+	})
+}
@@ -0,0 +1,143 @@
+package warsowlog
+
+import (
+	"log/slog"
+
+	"github.com/samber/lo"
+)
+
+// summarize builds the end of game record, it must be called within a command of the given game.
+func (p *Parser) summarize(game *Game) (slog.Level, []slog.Attr) {
+	level := slog.LevelInfo
+	attrs := []slog.Attr{
+		slog.String("game_type", game.GameType),
+		slog.String("match_id", game.MatchID()),
+		slog.Int("match", len(game.Matches())),
+		slog.Bool("full_game", true),
+	}
+
+	fullBot := lo.EveryBy(game.Playing(), func(p *Player) bool { return p.IsBot() })
+	summarized := p.summarized(game)
+	scores := make([]slog.Attr, 0, len(summarized))
+	players := lo.Map(summarized, func(p *Player, _ int) slog.Attr {
+		scores = append(
+			scores,
+			slog.Attr{
+				Key:   p.Name,
+				Value: slog.GroupValue(p.SlogScores()...),
+			},
+		)
+		return p.Slog(p.Name)
+	})
+	attrs = append(
+		attrs,
+		slog.Attr{
+			Key:   "players",
+			Value: slog.GroupValue(players...),
+		},
+	)
+	attrs = append(
+		attrs,
+		slog.Attr{
+			Key:   "scores",
+			Value: slog.GroupValue(scores...),
+		},
+	)
+	scoring := ScoringFor(game.GameType)
+	attrs = append(attrs, slog.String("scoring", scoring.Name()))
+	result, hasResult := ComputeResult(game, scoring, summarized)
+	if hasResult {
+		attrs = append(attrs, result.SlogAttrs()...)
+	}
+	if strategy := p.opts.MVP.For(game.GameType); strategy != nil {
+		if mvp, ok := MVP(game, strategy, summarized); ok {
+			attrs = append(attrs, slog.Group(
+				"mvp",
+				slog.String("name", mvp.Name),
+				slog.String("criterion", strategy.Name()),
+				slog.String("score", formatScore(strategy, strategy.Score(game, mvp))),
+			))
+		}
+	}
+	if game.Rounds() > 0 {
+		attrs = append(attrs, slog.Int("rounds", game.Rounds()))
+	}
+	if weapons := game.WeaponFrags(summarized); len(weapons) > 0 {
+		attrs = append(attrs, slog.Any("weapons", weapons))
+	}
+	if teamScores := game.TeamScores(); len(teamScores) > 0 {
+		attrs = append(attrs, slog.Any("team_scores", teamScores))
+	}
+	if len(p.opts.SummaryFields) > 0 {
+		attrs = append(attrs, p.computeFields(game, scoring, summarized, result))
+	}
+	attrs = append(attrs, slog.Bool("full_bot", fullBot))
+	attrs = append(attrs, slog.Time("start_at", game.match.StartAt))
+	attrs = append(attrs, slog.Duration("duration", game.Duration()))
+	if game.match.FirstBlood != "" {
+		attrs = append(attrs, slog.String("first_blood", game.match.FirstBlood))
+	}
+	attrs = append(attrs, slog.Bool("overtime", !game.match.OvertimeAt.IsZero()))
+	if overtime := game.Overtime(); overtime > 0 {
+		attrs = append(attrs, slog.Duration("overtime_duration", overtime))
+	}
+	if game.match.Paused > 0 {
+		attrs = append(attrs, slog.Duration("paused", game.match.Paused))
+	}
+	unranked := p.isUnranked(game)
+	attrs = append(attrs, slog.Bool("unranked", unranked))
+	if p.opts.Ratings != nil && !unranked {
+		attrs = append(attrs, p.rate(game, result, summarized))
+	}
+	if !fullBot {
+		level = slog.LevelWarn
+	}
+	return level, attrs
+}
+
+// summarized returns the players of the summary, spectators are left out and so are bots with BotsExclude.
+func (p *Parser) summarized(game *Game) []*Player {
+	if p.opts.Bots == BotsExclude {
+		return lo.Reject(game.Playing(), func(p *Player, _ int) bool { return p.IsBot() })
+	}
+	return game.Playing()
+}
+
+// isUnranked returns true if the game has too few human players or was too short to be counted
+// by ratings and leaderboards.
+func (p *Parser) isUnranked(game *Game) bool {
+	humans := lo.CountBy(game.Playing(), func(p *Player) bool { return !p.IsBot() })
+	return humans < p.opts.MinPlayers || game.Duration() < p.opts.MinDuration
+}
+
+// computeFields evaluates the user defined summary fields for each player.
+// A field failing to evaluate is left out and reported in an error field instead of failing the summary.
+func (p *Parser) computeFields(game *Game, scoring ScoringStrategy, players []*Player, result Result) slog.Attr {
+	ranking := Ranking(game, scoring, players)
+	computed := make([]slog.Attr, 0, len(ranking))
+	for i, player := range ranking {
+		vars := map[string]float64{
+			"total":    float64(player.Total()),
+			"frags":    float64(player.Frags()),
+			"suicides": float64(player.Suicides()),
+			"deaths":   float64(player.Deaths),
+			"kd":       player.KD(),
+			"net":      float64(player.NetScore()),
+			"rank":     float64(i + 1),
+			"players":  float64(len(ranking)),
+			"winner":   boolToFloat(result.Winner != "" && result.Winner == sideOf(player)),
+			"draw":     boolToFloat(result.Outcome == OutcomeDraw),
+		}
+		fields := make([]slog.Attr, 0, len(p.opts.SummaryFields))
+		for _, field := range p.opts.SummaryFields {
+			v, err := field.Eval(vars)
+			if err != nil {
+				fields = append(fields, slog.String(field.Name+"_error", err.Error()))
+				continue
+			}
+			fields = append(fields, slog.Float64(field.Name, v))
+		}
+		computed = append(computed, slog.Attr{Key: player.Name, Value: slog.GroupValue(fields...)})
+	}
+	return slog.Attr{Key: "computed", Value: slog.GroupValue(computed...)}
+}
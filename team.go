@@ -0,0 +1,35 @@
+package warsowlog
+
+import (
+	"strings"
+
+	"github.com/fabienjuif/warsowlog/color"
+)
+
+// Teams as normalized by NormalizeTeam.
+const (
+	TeamAlpha = "ALPHA"
+	TeamBeta  = "BETA"
+	// TeamPlayers is the team of every player in gametypes without teams
+	TeamPlayers   = "PLAYERS"
+	TeamSpectator = "SPECTATOR"
+)
+
+// NormalizeTeam returns the constant of a team name printed by the server, colors and case aside.
+// Unknown teams are only stripped of their colors.
+func NormalizeTeam(team string) string {
+	stripped := strings.TrimSpace(color.Strip(team))
+	switch upper := strings.ToUpper(stripped); upper {
+	case TeamAlpha, TeamBeta, TeamPlayers, TeamSpectator:
+		return upper
+	case "SPECTATORS":
+		return TeamSpectator
+	}
+	return stripped
+}
+
+// isTeam returns true if the players of the team play together, which is not the case
+// of the players of TeamPlayers nor of the spectators.
+func isTeam(team string) bool {
+	return team != "" && team != TeamPlayers && team != TeamSpectator
+}
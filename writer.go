@@ -0,0 +1,142 @@
+package warsowlog
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+)
+
+// Writer is an io.Writer feeding a Parser line by line, so another program can pipe the console
+// output of a server straight into it, for example as the Stdout of an exec.Cmd:
+//
+//	cmd.Stdout = warsowlog.NewWriter(ctx, warsowlog.Options{}, handler)
+//
+// Writer is not safe for concurrent use, like the console it reads.
+type Writer struct {
+	ctx    context.Context
+	parser *Parser
+	// pending is the beginning of a line not terminated yet
+	pending []byte
+}
+
+// NewWriter creates a Writer parsing with the given options, its records are sent to every handler.
+// Without handler the Handler of the options is used, then the default slog handler.
+func NewWriter(ctx context.Context, opts Options, handlers ...slog.Handler) *Writer {
+	switch len(handlers) {
+	case 0:
+	case 1:
+		opts.Handler = handlers[0]
+	default:
+		opts.Handler = multiHandler(handlers)
+	}
+	return &Writer{ctx: ctx, parser: NewParser(opts)}
+}
+
+// Parser returns the parser fed by the writer, to take snapshots of the current game.
+func (w *Writer) Parser() *Parser {
+	return w.parser
+}
+
+// Write parses every complete line of p, an unterminated line waits for the next write.
+func (w *Writer) Write(p []byte) (int, error) {
+	w.pending = append(w.pending, p...)
+	for {
+		i := bytes.IndexByte(w.pending, '\n')
+		if i < 0 {
+			break
+		}
+		w.parser.Parse(w.ctx, string(bytes.TrimSuffix(w.pending[:i], []byte("\r"))))
+		w.pending = w.pending[i+1:]
+	}
+	return len(p), nil
+}
+
+// Close parses the last line if it is not terminated by a newline and flushes the parser.
+func (w *Writer) Close() error {
+	if len(w.pending) > 0 {
+		w.parser.Parse(w.ctx, string(w.pending))
+		w.pending = nil
+	}
+	w.parser.Flush(w.ctx)
+	return nil
+}
+
+// ParseAll parses a whole log and returns the matches it contains with their timelines,
+// for tools that want the end result without registering handlers.
+// Matches without any record, like the one preceding the first gametype, are left out.
+func ParseAll(r io.Reader) ([]Match, error) {
+	w := NewWriter(context.Background(), Options{}, slog.NewJSONHandler(io.Discard, nil))
+	p := w.Parser()
+	games := make([]*Game, 0)
+	timelines := make(map[string][]Event)
+	p.OnEvent(func(_ context.Context, e Event) {
+		// the previous game is dropped by the parser when a new one starts, it is kept here
+		if game := p.game.Load(); len(games) == 0 || games[len(games)-1] != game {
+			games = append(games, game)
+		}
+		timelines[e.MatchID] = append(timelines[e.MatchID], e)
+	})
+	if _, err := io.Copy(w, r); err != nil {
+		return nil, err
+	}
+	if err := w.Close(); err != nil {
+		return nil, err
+	}
+
+	matches := make([]Match, 0)
+	for _, game := range games {
+		for _, m := range game.Matches() {
+			timeline, ok := timelines[m.ID]
+			if !ok {
+				continue
+			}
+			match := *m
+			match.Map = game.Map
+			match.Timeline = timeline
+			matches = append(matches, match)
+		}
+	}
+	return matches, nil
+}
+
+// multiHandler sends every record to all of its handlers.
+type multiHandler []slog.Handler
+
+func (h multiHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h multiHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h {
+		if handler.Enabled(ctx, record.Level) {
+			if err := handler.Handle(ctx, record.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h multiHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make(multiHandler, 0, len(h))
+	for _, handler := range h {
+		handlers = append(handlers, handler.WithAttrs(attrs))
+	}
+	return handlers
+}
+
+func (h multiHandler) WithGroup(name string) slog.Handler {
+	handlers := make(multiHandler, 0, len(h))
+	for _, handler := range h {
+		handlers = append(handlers, handler.WithGroup(name))
+	}
+	return handlers
+}